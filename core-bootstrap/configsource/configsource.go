@@ -0,0 +1,125 @@
+package configsource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+)
+
+var logger = logging.GetLogger("configsource")
+
+// Source supplies configuration values by their names
+type Source interface {
+	Name() string
+	Lookup(name string) (string, bool)
+}
+
+type envSource struct{}
+
+// NewEnvSource returns source reading process environment variables
+func NewEnvSource() Source {
+	return envSource{}
+}
+
+func (envSource) Name() string {
+	return "env"
+}
+
+func (envSource) Lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+type fileSource struct {
+	dir string
+}
+
+// NewFileSource returns source reading value of `name' from file `<dir>/<name>', e.g. mounted secret volume
+func NewFileSource(dir string) Source {
+	return fileSource{dir: dir}
+}
+
+func (s fileSource) Name() string {
+	return "file:" + s.dir
+}
+
+func (s fileSource) Lookup(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(string(data), "\r\n"), true
+}
+
+type mapSource struct {
+	name   string
+	values map[string]string
+}
+
+// NewMapSource returns source of fixed values, e.g. loaded from K8s Secret or ConfigMap
+func NewMapSource(name string, values map[string]string) Source {
+	return mapSource{name: name, values: values}
+}
+
+func (s mapSource) Name() string {
+	return s.name
+}
+
+func (s mapSource) Lookup(name string) (string, bool) {
+	value, ok := s.values[name]
+	return value, ok
+}
+
+// Layered looks values up in sources in order of their precedence and remembers which source supplied every value
+type Layered struct {
+	sources []Source
+	mutex   sync.Mutex
+	origins map[string]string
+}
+
+// NewLayered creates accessor over sources, the first source has the highest precedence
+func NewLayered(sources ...Source) *Layered {
+	return &Layered{sources: sources, origins: make(map[string]string)}
+}
+
+// Get is configuration accessor passed to tasks, it returns empty string for values missing in all sources
+func (l *Layered) Get(name string) string {
+	for _, source := range l.sources {
+		if value, ok := source.Lookup(name); ok {
+			l.recordOrigin(name, source.Name())
+			return value
+		}
+	}
+	l.recordOrigin(name, "")
+	return ""
+}
+
+// Origins returns names of sources by configuration value names read so far, empty source means the value is missing
+func (l *Layered) Origins() map[string]string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	origins := make(map[string]string, len(l.origins))
+	for name, source := range l.origins {
+		origins[name] = source
+	}
+	return origins
+}
+
+func (l *Layered) recordOrigin(name, source string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if previous, ok := l.origins[name]; ok && previous == source {
+		return
+	}
+	l.origins[name] = source
+	if source == "" {
+		logger.Debug("Configuration value '%s' is not set in any source", name)
+	} else {
+		logger.Info("Configuration value '%s' is supplied by %s (value redacted)", name, source)
+	}
+}
@@ -0,0 +1,43 @@
+package configsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayered_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "TEST_FILE_VALUE"), []byte("from-file\n"), 0600))
+	t.Setenv("TEST_FILE_VALUE", "from-env")
+	t.Setenv("TEST_MAP_VALUE", "from-env")
+	t.Setenv("TEST_ENV_VALUE", "from-env")
+
+	layered := NewLayered(
+		NewFileSource(dir),
+		NewMapSource("secret:test", map[string]string{"TEST_MAP_VALUE": "from-secret"}),
+		NewEnvSource(),
+	)
+
+	assert.Equal(t, "from-file", layered.Get("TEST_FILE_VALUE"))
+	assert.Equal(t, "from-secret", layered.Get("TEST_MAP_VALUE"))
+	assert.Equal(t, "from-env", layered.Get("TEST_ENV_VALUE"))
+	assert.Equal(t, "", layered.Get("TEST_MISSING_VALUE"))
+	assert.Equal(t, map[string]string{
+		"TEST_FILE_VALUE":    "file:" + dir,
+		"TEST_MAP_VALUE":     "secret:test",
+		"TEST_ENV_VALUE":     "env",
+		"TEST_MISSING_VALUE": "",
+	}, layered.Origins())
+}
+
+func TestFileSource_RejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "value"), []byte("secret"), 0600))
+
+	_, ok := NewFileSource(filepath.Join(dir, "nested")).Lookup("../value")
+
+	assert.False(t, ok)
+}
@@ -0,0 +1,38 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// NewSecretSource loads data of the secret once, missing secret is an error
+func NewSecretSource(ctx context.Context, namespace, name string) (configsource.Source, error) {
+	secret, err := utils.GetExistingSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration secret '%s': %w", name, err)
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("configuration secret '%s' not found in namespace '%s'", name, namespace)
+	}
+
+	values := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		values[key] = string(value)
+	}
+	return configsource.NewMapSource("secret:"+name, values), nil
+}
+
+// NewConfigMapSource loads data of the config map once, missing config map is an error
+func NewConfigMapSource(ctx context.Context, namespace, name string) (configsource.Source, error) {
+	configMap, err := utils.GetExistingConfigMap(ctx, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration config map '%s': %w", name, err)
+	}
+	if configMap == nil {
+		return nil, fmt.Errorf("configuration config map '%s' not found in namespace '%s'", name, namespace)
+	}
+	return configsource.NewMapSource("configmap:"+name, configMap.Data), nil
+}
@@ -0,0 +1,135 @@
+package configsource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Reader reads configuration values of a task collecting all problems instead of stopping at the first one
+type Reader struct {
+	accessor func(string) string
+	errs     []error
+}
+
+func NewReader(accessor func(string) string) *Reader {
+	return &Reader{accessor: accessor}
+}
+
+// Optional returns the value or empty string if it is not set
+func (r *Reader) Optional(name string) string {
+	return r.accessor(name)
+}
+
+// Required returns the value and records a problem if it is not set
+func (r *Reader) Required(name string) string {
+	value := r.accessor(name)
+	if value == "" {
+		r.errs = append(r.errs, fmt.Errorf("missed mandatory parameter `%s' value", name))
+	}
+	return value
+}
+
+// Boolean returns true only for case-insensitive `true' value
+func (r *Reader) Boolean(name string) bool {
+	return strings.ToLower(r.accessor(name)) == "true"
+}
+
+// Duration parses the value like `90s' or `720h', empty value is zero duration
+func (r *Reader) Duration(name string) time.Duration {
+	value := r.accessor(name)
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration < 0 {
+		err = fmt.Errorf("negative duration %s", value)
+	}
+	r.Check(name, err)
+	return duration
+}
+
+// PositiveInt parses the value as a positive integer, empty value gives defaultValue
+func (r *Reader) PositiveInt(name string, defaultValue int) int {
+	return r.intAtLeast(name, defaultValue, 1, "is not positive")
+}
+
+// NonNegativeInt parses the value as zero or a positive integer, empty value gives defaultValue
+func (r *Reader) NonNegativeInt(name string, defaultValue int) int {
+	return r.intAtLeast(name, defaultValue, 0, "is negative")
+}
+
+func (r *Reader) intAtLeast(name string, defaultValue, minimum int, problem string) int {
+	value := r.accessor(name)
+	if value == "" {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err == nil && number < minimum {
+		err = fmt.Errorf("%d %s", number, problem)
+	}
+	if err != nil {
+		r.Check(name, err)
+		return defaultValue
+	}
+	return number
+}
+
+// Check records the validation error of the value, nil error is ignored
+func (r *Reader) Check(name string, err error) {
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("invalid parameter `%s' value: %w", name, err))
+	}
+}
+
+// Fail records a problem not bound to a single value, e.g. inconsistent combination of values
+func (r *Reader) Fail(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Errorf(format, args...))
+}
+
+// Err returns all recorded problems, a single problem is returned as is
+func (r *Reader) Err() error {
+	if len(r.errs) == 1 {
+		return r.errs[0]
+	}
+	return errors.Join(r.errs...)
+}
+
+// ValidURL checks that non-empty value is an absolute http(s) URL
+func ValidURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("'%s' is not an absolute http(s) URL", value)
+	}
+	return nil
+}
+
+// ValidJSON checks that non-empty value is a JSON document
+func ValidJSON(value string) error {
+	if value == "" {
+		return nil
+	}
+	var document any
+	return json.Unmarshal([]byte(value), &document)
+}
+
+// ValidYAML checks that non-empty value is a YAML document
+func ValidYAML(value string) error {
+	if value == "" {
+		return nil
+	}
+	var document any
+	return yaml.Unmarshal([]byte(value), &document)
+}
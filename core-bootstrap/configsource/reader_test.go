@@ -0,0 +1,88 @@
+package configsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader_CollectsAllProblems(t *testing.T) {
+	values := map[string]string{
+		"ADDRESS": "not a url",
+		"RULE":    "{broken",
+		"ENABLED": "TRUE",
+		"MAX_AGE": "30d",
+	}
+	reader := NewReader(func(name string) string {
+		return values[name]
+	})
+
+	reader.Required("NAMESPACE")
+	reader.Check("ADDRESS", ValidURL(reader.Required("ADDRESS")))
+	reader.Check("RULE", ValidJSON(reader.Optional("RULE")))
+	reader.Check("CONFIG", ValidYAML(reader.Optional("CONFIG")))
+	assert.True(t, reader.Boolean("ENABLED"))
+	reader.Duration("MAX_AGE")
+
+	err := reader.Err()
+	assert.ErrorContains(t, err, "missed mandatory parameter `NAMESPACE' value")
+	assert.ErrorContains(t, err, "invalid parameter `ADDRESS' value")
+	assert.ErrorContains(t, err, "invalid parameter `RULE' value")
+	assert.ErrorContains(t, err, "invalid parameter `MAX_AGE' value")
+	assert.NotContains(t, err.Error(), "CONFIG")
+}
+
+func TestReader_NoProblems(t *testing.T) {
+	reader := NewReader(func(name string) string {
+		return "https://example.org"
+	})
+
+	reader.Check("ADDRESS", ValidURL(reader.Required("ADDRESS")))
+
+	assert.NoError(t, reader.Err())
+}
+
+func TestValidYAML(t *testing.T) {
+	assert.NoError(t, ValidYAML("apiVersion: nc.maas.config/v2\nkind: config\n"))
+	assert.Error(t, ValidYAML("key: [unclosed"))
+}
+
+func TestReader_Duration(t *testing.T) {
+	reader := NewReader(func(name string) string {
+		return map[string]string{"GRACE": "90m"}[name]
+	})
+
+	assert.Equal(t, 90*time.Minute, reader.Duration("GRACE"))
+	assert.Zero(t, reader.Duration("MISSING"))
+	assert.NoError(t, reader.Err())
+}
+
+func TestReader_PositiveInt(t *testing.T) {
+	reader := NewReader(func(name string) string {
+		return map[string]string{"LIMIT": "8", "ZERO": "0", "TEXT": "many"}[name]
+	})
+
+	assert.Equal(t, 8, reader.PositiveInt("LIMIT", 4))
+	assert.Equal(t, 4, reader.PositiveInt("MISSING", 4))
+	assert.NoError(t, reader.Err())
+
+	assert.Equal(t, 4, reader.PositiveInt("ZERO", 4))
+	assert.Equal(t, 4, reader.PositiveInt("TEXT", 4))
+	err := reader.Err()
+	assert.ErrorContains(t, err, "invalid parameter `ZERO' value: 0 is not positive")
+	assert.ErrorContains(t, err, "invalid parameter `TEXT' value")
+}
+
+func TestReader_NonNegativeInt(t *testing.T) {
+	reader := NewReader(func(name string) string {
+		return map[string]string{"RETRIES": "0", "NEGATIVE": "-1"}[name]
+	})
+
+	assert.Equal(t, 0, reader.NonNegativeInt("RETRIES", 3))
+	assert.Equal(t, 3, reader.NonNegativeInt("MISSING", 3))
+	assert.NoError(t, reader.Err())
+
+	assert.Equal(t, 3, reader.NonNegativeInt("NEGATIVE", 3))
+	assert.ErrorContains(t, reader.Err(), "invalid parameter `NEGATIVE' value: -1 is negative")
+}
@@ -0,0 +1,222 @@
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+)
+
+const (
+	KubernetesSink = "kubernetes"
+	VaultSink      = "vault"
+
+	// AnnotationPrefix is the prefix of annotations owned by core-bootstrap, other annotations of stored
+	// credentials belong to others and are never written by sinks
+	AnnotationPrefix = "core-bootstrap.qubership.org/"
+	// ContentHashAnnotation keeps ContentHash of values written last, values not matching it are drifted
+	ContentHashAnnotation = AnnotationPrefix + "content-hash"
+	// ContentKeysAnnotation lists comma separated keys of values written last, keys added by others are not hashed
+	ContentKeysAnnotation = AnnotationPrefix + "content-keys"
+	// RotationRequestAnnotation keeps the explicit rotation request served last, the same request is not served again
+	RotationRequestAnnotation = AnnotationPrefix + "rotation-request"
+
+	driftDetail = "modified outside core-bootstrap since it was written last"
+)
+
+// Credentials is a named set of credential values together with annotations describing them, e.g. rotation state
+type Credentials struct {
+	Data        map[string][]byte
+	Annotations map[string]string
+	// Created is the time the credentials were stored first, zero if unknown
+	Created time.Time
+	// Version of read credentials, writing credentials with non-empty version fails if they were modified since
+	Version string
+}
+
+// Sink stores credentials produced by tasks: Consul tokens, database and MaaS agent credentials
+type Sink interface {
+	// Kind names the kind of stored objects in reports, e.g. `Secret'
+	Kind() string
+	// Read returns the stored credentials or nil if there are none
+	Read(ctx context.Context, name string) (*Credentials, error)
+	// Write replaces values and annotations with AnnotationPrefix of the credentials, other annotations are kept
+	Write(ctx context.Context, name string, credentials Credentials) error
+	// Delete removes the credentials, absent ones are ignored
+	Delete(ctx context.Context, name string) error
+}
+
+// Save replaces values of the credentials keeping owned annotations of the stored ones and adding the given annotations
+func Save(ctx context.Context, sink Sink, name string, data map[string][]byte, annotations map[string]string) error {
+	existing, err := sink.Read(ctx, name)
+	if err != nil {
+		return err
+	}
+	return SaveOver(ctx, sink, existing, name, data, annotations)
+}
+
+// SaveOver is Save of credentials already read, it fails if the credentials were modified after they were read.
+// Drift of the existing credentials is reported before they are overwritten.
+func SaveOver(ctx context.Context, sink Sink, existing *Credentials, name string, data map[string][]byte, annotations map[string]string) error {
+	ReportDrift(ctx, sink, name, existing)
+	credentials := Credentials{Data: data, Annotations: map[string]string{}}
+	if existing != nil {
+		maps.Copy(credentials.Annotations, OwnedAnnotations(existing.Annotations))
+		credentials.Version = existing.Version
+	}
+	maps.Copy(credentials.Annotations, annotations)
+	credentials.Annotations[ContentHashAnnotation] = ContentHash(data)
+	credentials.Annotations[ContentKeysAnnotation] = strings.Join(slices.Sorted(maps.Keys(data)), ",")
+	return sink.Write(ctx, name, credentials)
+}
+
+// OwnedAnnotations returns annotations with AnnotationPrefix
+func OwnedAnnotations(annotations map[string]string) map[string]string {
+	owned := make(map[string]string)
+	for key, value := range annotations {
+		if strings.HasPrefix(key, AnnotationPrefix) {
+			owned[key] = value
+		}
+	}
+	return owned
+}
+
+// OwnedData returns values of the credentials with keys written last by core-bootstrap, all values if the keys
+// are unknown
+func OwnedData(credentials *Credentials) map[string][]byte {
+	keys, ok := credentials.Annotations[ContentKeysAnnotation]
+	if !ok {
+		return credentials.Data
+	}
+	owned := make(map[string][]byte)
+	for _, key := range strings.Split(keys, ",") {
+		if value, ok := credentials.Data[key]; ok {
+			owned[key] = value
+		}
+	}
+	return owned
+}
+
+// RotationRequest reads the explicit rotation request from the value: empty value and `false' request nothing, any
+// other value, e.g. `true' or the date of the request, is served once for each credentials
+func RotationRequest(reader *configsource.Reader, name string) string {
+	request := strings.TrimSpace(reader.Optional(name))
+	if strings.EqualFold(request, "false") {
+		return ""
+	}
+	return request
+}
+
+// RotationRequested tells if the explicit rotation request was not served for the credentials with the annotations yet
+func RotationRequested(request string, annotations map[string]string) bool {
+	return request != "" && annotations[RotationRequestAnnotation] != request
+}
+
+// ServedRotationRequest returns annotations recording the request as served by rotation, nil if there is no request
+func ServedRotationRequest(request string) map[string]string {
+	if request == "" {
+		return nil
+	}
+	return map[string]string{RotationRequestAnnotation: request}
+}
+
+// ContentHash returns SHA-256 of the values
+func ContentHash(data map[string][]byte) string {
+	// keys of marshalled map are sorted
+	content, _ := json.Marshal(data)
+	hash := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// DriftDetail describes drift of the credentials or returns empty string if their values are the ones written last.
+// Only values of keys written by core-bootstrap are compared. Credentials without ContentHashAnnotation, e.g. written
+// by previous versions, are not drifted.
+func DriftDetail(credentials *Credentials) string {
+	if credentials == nil {
+		return ""
+	}
+	written, ok := credentials.Annotations[ContentHashAnnotation]
+	if !ok || written == ContentHash(OwnedData(credentials)) {
+		return ""
+	}
+	return driftDetail
+}
+
+// Drift returns drift report of the credentials for plans of tasks not writing them, nil if they are not drifted
+func Drift(sink Sink, name string, credentials *Credentials) []taskmanager.Change {
+	if detail := DriftDetail(credentials); detail != "" {
+		return []taskmanager.Change{{Action: taskmanager.ActionDrift, Kind: sink.Kind(), Name: name, Detail: detail}}
+	}
+	return nil
+}
+
+// ReportDrift records drift of the credentials in the execution report
+func ReportDrift(ctx context.Context, sink Sink, name string, credentials *Credentials) {
+	for _, change := range Drift(sink, name, credentials) {
+		taskmanager.RecordChange(ctx, change)
+	}
+}
+
+// Restorer captures the current state of the credentials and returns function restoring it:
+// previous values and owned annotations if the credentials exist or their absence otherwise
+func Restorer(ctx context.Context, sink Sink, name string) (func(context.Context) error, error) {
+	existing, err := sink.Read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return Restore(sink, name, existing), nil
+}
+
+// Restore is Restorer of credentials already read, nil existing credentials are restored by deletion
+func Restore(sink Sink, name string, existing *Credentials) func(context.Context) error {
+	if existing == nil {
+		return func(ctx context.Context) error {
+			return sink.Delete(ctx, name)
+		}
+	}
+	previous := Credentials{Data: OwnedData(existing), Annotations: OwnedAnnotations(existing.Annotations)}
+	return func(ctx context.Context) error {
+		return sink.Write(ctx, name, previous)
+	}
+}
+
+// Config selects the sink of a deployment: `kubernetes' (default) or `vault'
+type Config struct {
+	Sink  string
+	Vault VaultConfig
+}
+
+// ReadConfig reads CREDENTIALS_SINK and, for Vault sink, VAULT_* values recording problems in the reader
+func ReadConfig(reader *configsource.Reader) Config {
+	config := Config{Sink: reader.Optional("CREDENTIALS_SINK")}
+	switch config.Sink {
+	case "", KubernetesSink:
+		config.Sink = KubernetesSink
+	case VaultSink:
+		config.Vault = VaultConfig{
+			Address:            reader.Required("VAULT_ADDR"),
+			Token:              reader.Optional("VAULT_TOKEN"),
+			Namespace:          reader.Optional("VAULT_NAMESPACE"),
+			Mount:              reader.Optional("VAULT_KV_MOUNT"),
+			PathPrefix:         reader.Optional("VAULT_KV_PATH_PREFIX"),
+			KubernetesRole:     reader.Optional("VAULT_K8S_ROLE"),
+			KubernetesAuthPath: reader.Optional("VAULT_K8S_AUTH_PATH"),
+			KubernetesJWTFile:  reader.Optional("VAULT_K8S_TOKEN_FILE"),
+		}
+		reader.Check("VAULT_ADDR", configsource.ValidURL(config.Vault.Address))
+		if config.Vault.Token == "" && config.Vault.KubernetesRole == "" {
+			reader.Fail("one of VAULT_TOKEN and VAULT_K8S_ROLE must be set for vault credentials sink")
+		}
+	default:
+		reader.Check("CREDENTIALS_SINK", fmt.Errorf("unknown sink '%s', expected %s or %s", config.Sink, KubernetesSink, VaultSink))
+	}
+	return config
+}
@@ -0,0 +1,198 @@
+package credentials
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink keeps credentials in memory, versions are incremented on each write
+type memorySink map[string]Credentials
+
+const maxAgeAnnotation = AnnotationPrefix + "max-age"
+
+func (m memorySink) Kind() string {
+	return "Memory"
+}
+
+func (m memorySink) Read(_ context.Context, name string) (*Credentials, error) {
+	stored, ok := m[name]
+	if !ok {
+		return nil, nil
+	}
+	return &stored, nil
+}
+
+func (m memorySink) Write(_ context.Context, name string, credentials Credentials) error {
+	stored := m[name]
+	version, _ := strconv.Atoi(stored.Version)
+	annotations := OwnedAnnotations(credentials.Annotations)
+	for key, value := range stored.Annotations {
+		if !strings.HasPrefix(key, AnnotationPrefix) {
+			annotations[key] = value
+		}
+	}
+	m[name] = Credentials{Data: credentials.Data, Annotations: annotations, Version: strconv.Itoa(version + 1)}
+	return nil
+}
+
+func (m memorySink) Delete(_ context.Context, name string) error {
+	delete(m, name)
+	return nil
+}
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	rotatedAnnotation := AnnotationPrefix + "rotated"
+	sink := memorySink{"token": {
+		Data:        map[string][]byte{"token": []byte("old")},
+		Annotations: map[string]string{maxAgeAnnotation: "1h", rotatedAnnotation: "no", "owner": "team"},
+	}}
+
+	require.NoError(t, Save(ctx, sink, "token", map[string][]byte{"token": []byte("new")}, map[string]string{rotatedAnnotation: "yes"}))
+	assert.Equal(t, map[string][]byte{"token": []byte("new")}, sink["token"].Data)
+	assert.Equal(t, map[string]string{
+		maxAgeAnnotation:      "1h",
+		rotatedAnnotation:     "yes",
+		"owner":               "team",
+		ContentHashAnnotation: ContentHash(map[string][]byte{"token": []byte("new")}),
+		ContentKeysAnnotation: "token",
+	}, sink["token"].Annotations)
+
+	require.NoError(t, Save(ctx, sink, "created", map[string][]byte{"password": []byte("secret"), "username": []byte("agent")}, nil))
+	assert.Equal(t, map[string]string{
+		ContentHashAnnotation: ContentHash(map[string][]byte{"password": []byte("secret"), "username": []byte("agent")}),
+		ContentKeysAnnotation: "password,username",
+	}, sink["created"].Annotations)
+}
+
+// recordingSink records credentials passed to Write
+type recordingSink struct {
+	memorySink
+	written []Credentials
+}
+
+func (r *recordingSink) Write(ctx context.Context, name string, credentials Credentials) error {
+	r.written = append(r.written, credentials)
+	return r.memorySink.Write(ctx, name, credentials)
+}
+
+func TestSave_OwnedAnnotationsOnly(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{memorySink: memorySink{"token": {
+		Data:        map[string][]byte{"token": []byte("old")},
+		Annotations: map[string]string{maxAgeAnnotation: "1h", "kubectl.kubernetes.io/last-applied-configuration": "{}"},
+	}}}
+
+	require.NoError(t, Save(ctx, sink, "token", map[string][]byte{"token": []byte("new")}, nil))
+	restore, err := Restorer(ctx, sink, "token")
+	require.NoError(t, err)
+	require.NoError(t, restore(ctx))
+
+	require.Len(t, sink.written, 2)
+	for _, written := range sink.written {
+		assert.NotContains(t, written.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		assert.Equal(t, "1h", written.Annotations[maxAgeAnnotation])
+	}
+	assert.Equal(t, "{}", sink.memorySink["token"].Annotations["kubectl.kubernetes.io/last-applied-configuration"])
+}
+
+func TestContentHash(t *testing.T) {
+	hash := ContentHash(map[string][]byte{"username": []byte("agent"), "password": []byte("secret")})
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", hash)
+	assert.Equal(t, hash, ContentHash(map[string][]byte{"password": []byte("secret"), "username": []byte("agent")}), "order of keys does not matter")
+	assert.NotEqual(t, hash, ContentHash(map[string][]byte{"username": []byte("agent"), "password": []byte("changed")}))
+}
+
+func TestDrift(t *testing.T) {
+	ctx := context.Background()
+	sink := memorySink{"legacy": {Data: map[string][]byte{"password": []byte("old")}}}
+	require.NoError(t, Save(ctx, sink, "written", map[string][]byte{"password": []byte("secret")}, nil))
+
+	assert.Empty(t, DriftDetail(nil), "absent credentials")
+	legacy := sink["legacy"]
+	assert.Empty(t, DriftDetail(&legacy), "credentials written without content hash")
+	written := sink["written"]
+	assert.Empty(t, DriftDetail(&written))
+	assert.Nil(t, Drift(sink, "written", &written))
+
+	written.Data = map[string][]byte{"password": []byte("edited")}
+	assert.Equal(t, driftDetail, DriftDetail(&written))
+	assert.Equal(t, []taskmanager.Change{{Action: taskmanager.ActionDrift, Kind: "Memory", Name: "written", Detail: driftDetail}}, Drift(sink, "written", &written))
+
+	written.Data = map[string][]byte{"password": []byte("secret"), "ca.crt": []byte("added by others")}
+	assert.Empty(t, DriftDetail(&written), "keys added by others are not compared")
+	assert.Equal(t, map[string][]byte{"password": []byte("secret")}, OwnedData(&written))
+	written.Data = map[string][]byte{"ca.crt": []byte("added by others")}
+	assert.Equal(t, driftDetail, DriftDetail(&written), "written key is removed")
+
+	require.NoError(t, SaveOver(ctx, sink, &written, "written", map[string][]byte{"password": []byte("restored")}, nil))
+	rewritten := sink["written"]
+	assert.Empty(t, DriftDetail(&rewritten), "content hash is updated on write")
+}
+
+func TestRotationRequest(t *testing.T) {
+	values := map[string]string{"ROTATE": "true", "OFF": "False", "DATED": " 2025-06-01 "}
+	reader := configsource.NewReader(func(name string) string { return values[name] })
+	assert.Equal(t, "true", RotationRequest(reader, "ROTATE"))
+	assert.Empty(t, RotationRequest(reader, "OFF"))
+	assert.Empty(t, RotationRequest(reader, "UNSET"))
+	assert.Equal(t, "2025-06-01", RotationRequest(reader, "DATED"))
+
+	assert.False(t, RotationRequested("", nil), "no request")
+	assert.True(t, RotationRequested("true", nil), "request not served yet")
+	served := ServedRotationRequest("true")
+	assert.False(t, RotationRequested("true", served), "request is served once")
+	assert.True(t, RotationRequested("2025-06-01", served), "new request")
+	assert.Nil(t, ServedRotationRequest(""))
+}
+
+func TestRestorer(t *testing.T) {
+	ctx := context.Background()
+	sink := memorySink{"existing": {Data: map[string][]byte{"password": []byte("old")}, Annotations: map[string]string{"a": "b"}}}
+
+	restoreExisting, err := Restorer(ctx, sink, "existing")
+	require.NoError(t, err)
+	restoreAbsent, err := Restorer(ctx, sink, "absent")
+	require.NoError(t, err)
+
+	require.NoError(t, Save(ctx, sink, "existing", map[string][]byte{"password": []byte("new")}, map[string]string{"c": "d"}))
+	require.NoError(t, Save(ctx, sink, "absent", map[string][]byte{"password": []byte("new")}, nil))
+
+	require.NoError(t, restoreExisting(ctx))
+	require.NoError(t, restoreAbsent(ctx))
+	assert.Equal(t, "old", string(sink["existing"].Data["password"]))
+	assert.Equal(t, map[string]string{"a": "b"}, sink["existing"].Annotations)
+	assert.NotContains(t, sink, "absent")
+}
+
+func TestReadConfig(t *testing.T) {
+	read := func(values map[string]string) (Config, error) {
+		reader := configsource.NewReader(func(name string) string { return values[name] })
+		config := ReadConfig(reader)
+		return config, reader.Err()
+	}
+
+	config, err := read(nil)
+	require.NoError(t, err)
+	assert.Equal(t, KubernetesSink, config.Sink)
+
+	config, err = read(map[string]string{"CREDENTIALS_SINK": "vault", "VAULT_ADDR": "https://vault:8200", "VAULT_K8S_ROLE": "core"})
+	require.NoError(t, err)
+	assert.Equal(t, VaultConfig{Address: "https://vault:8200", KubernetesRole: "core"}, config.Vault)
+
+	_, err = read(map[string]string{"CREDENTIALS_SINK": "vault", "VAULT_ADDR": "https://vault:8200"})
+	assert.ErrorContains(t, err, "one of VAULT_TOKEN and VAULT_K8S_ROLE must be set")
+
+	_, err = read(map[string]string{"CREDENTIALS_SINK": "vault", "VAULT_TOKEN": "token"})
+	assert.ErrorContains(t, err, "VAULT_ADDR")
+
+	_, err = read(map[string]string{"CREDENTIALS_SINK": "file"})
+	assert.ErrorContains(t, err, "unknown sink 'file'")
+}
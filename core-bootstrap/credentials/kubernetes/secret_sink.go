@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"context"
+	"path"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/httpclient"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// SecretSink stores credentials in Opaque secrets of the namespace, annotations are annotations of the secret
+type SecretSink struct {
+	namespace string
+}
+
+func NewSecretSink(namespace string) *SecretSink {
+	return &SecretSink{namespace: namespace}
+}
+
+// ReadSink returns the sink configured by credentials.ReadConfig, Vault is called with TLS settings and client profile of `vault' endpoint
+func ReadSink(reader *configsource.Reader, namespace string) credentials.Sink {
+	config := credentials.ReadConfig(reader)
+	if config.Sink != credentials.VaultSink {
+		return NewSecretSink(namespace)
+	}
+	return NewSink(config, namespace, utils.NewEndpointClient(reader, httpclient.Vault, namespace))
+}
+
+// NewSink returns the sink selected by the configuration, Vault credentials are stored under the namespace path
+// unless VAULT_KV_PATH_PREFIX is set
+func NewSink(config credentials.Config, namespace string, httpClient *resty.Client) credentials.Sink {
+	if config.Sink != credentials.VaultSink {
+		return NewSecretSink(namespace)
+	}
+	vaultConfig := config.Vault
+	if vaultConfig.PathPrefix == "" {
+		vaultConfig.PathPrefix = path.Join("core-bootstrap", namespace)
+	}
+	return credentials.NewVault(httpClient, vaultConfig)
+}
+
+func (s *SecretSink) Kind() string {
+	return utils.SecretV1.Kind()
+}
+
+func (s *SecretSink) Read(ctx context.Context, name string) (*credentials.Credentials, error) {
+	secret, err := utils.GetExistingSecret(ctx, s.namespace, name)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	return &credentials.Credentials{
+		Data:        secret.Data,
+		Annotations: secret.Annotations,
+		Created:     secret.CreationTimestamp.Time,
+		Version:     secret.ResourceVersion,
+	}, nil
+}
+
+// ManagedLabels are set on all secrets written by the sink, secrets are managed by the same field manager
+// which applies them
+var ManagedLabels = map[string]string{
+	"app.kubernetes.io/part-of":    "Cloud-Core",
+	"app.kubernetes.io/managed-by": utils.FieldManager,
+}
+
+// Write applies values and owned annotations of the secret keeping labels, annotations and keys set by others,
+// the apply is based on the version of read credentials if it is set
+func (s *SecretSink) Write(ctx context.Context, name string, stored credentials.Credentials) error {
+	annotations := credentials.OwnedAnnotations(stored.Annotations)
+	return utils.ApplySecret(ctx, s.namespace, name, ManagedLabels, annotations, stored.Data, stored.Version)
+}
+
+func (s *SecretSink) Delete(ctx context.Context, name string) error {
+	return utils.DeleteSecret(ctx, s.namespace, name)
+}
@@ -0,0 +1,173 @@
+package credentials
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"sigs.k8s.io/yaml"
+)
+
+// CharacterClass names a set of characters passwords are drawn from
+type CharacterClass string
+
+const (
+	Lowercase CharacterClass = "lower"
+	Uppercase CharacterClass = "upper"
+	Digits    CharacterClass = "digits"
+	Symbols   CharacterClass = "symbols"
+
+	// DefaultSymbols are symbols not requiring escaping in URLs, shells, YAML and connection strings
+	DefaultSymbols = "-_.~"
+
+	MinPasswordLength = 12
+	MaxPasswordLength = 256
+)
+
+var classCharacters = map[CharacterClass]string{
+	Lowercase: "abcdefghijklmnopqrstuvwxyz",
+	Uppercase: "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	Digits:    "0123456789",
+}
+
+// TargetMaaS is the target of MaaS agent passwords, every consumer of generated passwords is a target having its own policy
+const TargetMaaS = "maas"
+
+// DefaultPasswordPolicies are policies of the targets unless `<TARGET>_PASSWORD_POLICY' overrides them
+var DefaultPasswordPolicies = map[string]PasswordPolicy{
+	TargetMaaS: {Length: 24, Classes: []CharacterClass{Lowercase, Uppercase, Digits}},
+}
+
+// PasswordPolicy defines length of generated passwords and character classes they are drawn from,
+// every password has at least one character of each class
+type PasswordPolicy struct {
+	Length  int              `json:"length"`
+	Classes []CharacterClass `json:"classes"`
+	// Symbols replaces DefaultSymbols for the symbols class
+	Symbols string `json:"symbols,omitempty"`
+}
+
+func (p PasswordPolicy) Validate() error {
+	if p.Length < MinPasswordLength || p.Length > MaxPasswordLength {
+		return fmt.Errorf("password length %d is out of range %d-%d", p.Length, MinPasswordLength, MaxPasswordLength)
+	}
+	if len(p.Classes) == 0 {
+		return errors.New("at least one character class is required")
+	}
+	seen := make(map[CharacterClass]bool)
+	for _, class := range p.Classes {
+		if _, err := p.characters(class); err != nil {
+			return err
+		}
+		if seen[class] {
+			return fmt.Errorf("duplicate character class '%s'", class)
+		}
+		seen[class] = true
+	}
+	return nil
+}
+
+func (p PasswordPolicy) characters(class CharacterClass) (string, error) {
+	if class == Symbols {
+		if p.Symbols == "" {
+			return DefaultSymbols, nil
+		}
+		if strings.ContainsAny(p.Symbols, classCharacters[Lowercase]+classCharacters[Uppercase]+classCharacters[Digits]) {
+			return "", fmt.Errorf("symbols '%s' contain letters or digits", p.Symbols)
+		}
+		return p.Symbols, nil
+	}
+	characters, ok := classCharacters[class]
+	if !ok {
+		return "", fmt.Errorf("unknown character class '%s', expected one of %s, %s, %s, %s", class, Lowercase, Uppercase, Digits, Symbols)
+	}
+	return characters, nil
+}
+
+// Generate returns a new password of the policy drawn from crypto/rand
+func (p PasswordPolicy) Generate() (string, error) {
+	return p.generate(rand.Reader)
+}
+
+func (p PasswordPolicy) generate(random io.Reader) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", fmt.Errorf("invalid password policy: %w", err)
+	}
+	password := make([]rune, 0, p.Length)
+	var all strings.Builder
+	for _, class := range p.Classes {
+		characters, _ := p.characters(class)
+		all.WriteString(characters)
+		character, err := pick(random, []rune(characters))
+		if err != nil {
+			return "", err
+		}
+		password = append(password, character)
+	}
+	alphabet := []rune(all.String())
+	for len(password) < p.Length {
+		character, err := pick(random, alphabet)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, character)
+	}
+
+	// characters of each class are not kept at the beginning
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(random, i+1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+func pick(random io.Reader, alphabet []rune) (rune, error) {
+	i, err := randomInt(random, len(alphabet))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[i], nil
+}
+
+// randomInt returns uniformly distributed integer in [0, n)
+func randomInt(random io.Reader, n int) (int, error) {
+	i, err := rand.Int(random, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("error reading random source: %w", err)
+	}
+	return int(i.Int64()), nil
+}
+
+// ParsePasswordPolicy parses YAML or JSON policy, fields which are not set are taken from defaults
+func ParsePasswordPolicy(data string, defaults PasswordPolicy) (PasswordPolicy, error) {
+	policy := defaults
+	policy.Classes = nil
+	if err := yaml.UnmarshalStrict([]byte(data), &policy); err != nil {
+		return PasswordPolicy{}, err
+	}
+	if policy.Classes == nil {
+		policy.Classes = defaults.Classes
+	}
+	return policy, policy.Validate()
+}
+
+// ReadPasswordPolicy returns the policy of the target overridden by `<TARGET>_PASSWORD_POLICY' value,
+// e.g. {"length": 32, "classes": ["lower", "upper", "digits"]} for MAAS_PASSWORD_POLICY
+func ReadPasswordPolicy(reader *configsource.Reader, target string) PasswordPolicy {
+	name := strings.ToUpper(target) + "_PASSWORD_POLICY"
+	defaults := DefaultPasswordPolicies[target]
+	value := reader.Optional(name)
+	if value == "" {
+		return defaults
+	}
+	policy, err := ParsePasswordPolicy(value, defaults)
+	reader.Check(name, err)
+	return policy
+}
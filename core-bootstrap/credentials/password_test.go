@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy exhausted")
+}
+
+func TestPasswordPolicy_Generate(t *testing.T) {
+	policy := PasswordPolicy{Length: 16, Classes: []CharacterClass{Lowercase, Digits, Symbols}, Symbols: "!@"}
+	seen := make(map[string]bool)
+	for range 100 {
+		password, err := policy.Generate()
+		require.NoError(t, err)
+		assert.Len(t, password, 16)
+		assert.True(t, strings.ContainsAny(password, classCharacters[Lowercase]), password)
+		assert.True(t, strings.ContainsAny(password, classCharacters[Digits]), password)
+		assert.True(t, strings.ContainsAny(password, "!@"), password)
+		assert.Empty(t, strings.Trim(password, classCharacters[Lowercase]+classCharacters[Digits]+"!@"), password)
+		seen[password] = true
+	}
+	assert.Len(t, seen, 100)
+
+	_, err := policy.generate(failingReader{})
+	assert.ErrorContains(t, err, "entropy exhausted")
+}
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	for _, policy := range DefaultPasswordPolicies {
+		require.NoError(t, policy.Validate())
+	}
+	assert.EqualError(t, PasswordPolicy{Length: 8, Classes: []CharacterClass{Digits}}.Validate(), "password length 8 is out of range 12-256")
+	assert.EqualError(t, PasswordPolicy{Length: 16}.Validate(), "at least one character class is required")
+	assert.EqualError(t, PasswordPolicy{Length: 16, Classes: []CharacterClass{Digits, Digits}}.Validate(), "duplicate character class 'digits'")
+	assert.ErrorContains(t, PasswordPolicy{Length: 16, Classes: []CharacterClass{"hex"}}.Validate(), "unknown character class 'hex'")
+	assert.EqualError(t, PasswordPolicy{Length: 16, Classes: []CharacterClass{Symbols}, Symbols: "a!"}.Validate(), "symbols 'a!' contain letters or digits")
+}
+
+func TestReadPasswordPolicy(t *testing.T) {
+	read := func(value string) (PasswordPolicy, error) {
+		reader := configsource.NewReader(func(name string) string {
+			if name == "MAAS_PASSWORD_POLICY" {
+				return value
+			}
+			return ""
+		})
+		policy := ReadPasswordPolicy(reader, TargetMaaS)
+		return policy, reader.Err()
+	}
+
+	policy, err := read("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPasswordPolicies[TargetMaaS], policy)
+
+	policy, err = read("length: 40")
+	require.NoError(t, err)
+	assert.Equal(t, PasswordPolicy{Length: 40, Classes: DefaultPasswordPolicies[TargetMaaS].Classes}, policy)
+
+	policy, err = read(`{"classes": ["lower", "symbols"], "symbols": "#%"}`)
+	require.NoError(t, err)
+	assert.Equal(t, PasswordPolicy{Length: 24, Classes: []CharacterClass{Lowercase, Symbols}, Symbols: "#%"}, policy)
+
+	_, err = read("size: 40")
+	assert.ErrorContains(t, err, "MAAS_PASSWORD_POLICY")
+}
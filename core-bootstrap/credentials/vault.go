@@ -0,0 +1,277 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+)
+
+const (
+	KindVaultSecret = "VaultSecret"
+
+	defaultVaultMount             = "secret"
+	defaultVaultKubernetesAuth    = "kubernetes"
+	defaultVaultKubernetesJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var logger = logging.GetLogger("credentials")
+
+// VaultConfig configures Vault KV v2 sink: a static token or Kubernetes auth method login with service account token
+type VaultConfig struct {
+	Address string
+	Token   string
+	// Namespace is the Vault Enterprise namespace
+	Namespace string
+	// Mount is the path of KV v2 secrets engine, `secret' by default
+	Mount string
+	// PathPrefix is prepended to credentials names
+	PathPrefix         string
+	KubernetesRole     string
+	KubernetesAuthPath string
+	KubernetesJWTFile  string
+}
+
+// Vault stores credentials in KV v2 secrets engine, annotations are kept in custom metadata of the secret
+type Vault struct {
+	httpClient *resty.Client
+	config     VaultConfig
+	mutex      sync.Mutex
+	token      string
+}
+
+func NewVault(httpClient *resty.Client, config VaultConfig) *Vault {
+	if config.Mount == "" {
+		config.Mount = defaultVaultMount
+	}
+	if config.KubernetesAuthPath == "" {
+		config.KubernetesAuthPath = defaultVaultKubernetesAuth
+	}
+	if config.KubernetesJWTFile == "" {
+		config.KubernetesJWTFile = defaultVaultKubernetesJWTFile
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	return &Vault{httpClient: httpClient, config: config, token: config.Token}
+}
+
+func (v *Vault) Kind() string {
+	return KindVaultSecret
+}
+
+type vaultErrors struct {
+	Errors []string `json:"errors"`
+}
+
+type vaultMetadata struct {
+	Data struct {
+		CreatedTime    time.Time         `json:"created_time"`
+		CurrentVersion int               `json:"current_version"`
+		CustomMetadata map[string]string `json:"custom_metadata"`
+	} `json:"data"`
+}
+
+type vaultData struct {
+	Data struct {
+		Data map[string]string `json:"data"`
+	} `json:"data"`
+}
+
+func (v *Vault) Read(ctx context.Context, name string) (*Credentials, error) {
+	var metadata vaultMetadata
+	status, err := v.do(ctx, http.MethodGet, v.url("metadata", name), nil, &metadata)
+	if err != nil || status == http.StatusNotFound {
+		return nil, err
+	}
+	var data vaultData
+	status, err = v.do(ctx, http.MethodGet, v.url("data", name), nil, &data)
+	// latest version is deleted or destroyed
+	if err != nil || status == http.StatusNotFound {
+		return nil, err
+	}
+
+	credentials := &Credentials{
+		Data:        make(map[string][]byte, len(data.Data.Data)),
+		Annotations: metadata.Data.CustomMetadata,
+		Created:     metadata.Data.CreatedTime,
+		Version:     strconv.Itoa(metadata.Data.CurrentVersion),
+	}
+	for key, value := range data.Data.Data {
+		credentials.Data[key] = []byte(value)
+	}
+	return credentials, nil
+}
+
+// Write replaces owned custom metadata of the secret with annotations keeping metadata of others, then stores a new
+// version of the secret using check-and-set on the read version if there is one. Metadata is written first, so a new
+// version never lacks its content hash, and it is restored if the version is not stored.
+func (v *Vault) Write(ctx context.Context, name string, credentials Credentials) error {
+	values := make(map[string]string, len(credentials.Data))
+	for key, value := range credentials.Data {
+		values[key] = string(value)
+	}
+	body := map[string]interface{}{"data": values}
+	if credentials.Version != "" {
+		version, err := strconv.Atoi(credentials.Version)
+		if err != nil {
+			return fmt.Errorf("invalid version '%s' of vault secret %s: %w", credentials.Version, name, err)
+		}
+		body["options"] = map[string]int{"cas": version}
+	}
+
+	var metadata vaultMetadata
+	status, err := v.do(ctx, http.MethodGet, v.url("metadata", name), nil, &metadata)
+	if err != nil {
+		return err
+	}
+	previous := metadata.Data.CustomMetadata
+	if previous == nil {
+		previous = map[string]string{}
+	}
+	customMetadata := OwnedAnnotations(credentials.Annotations)
+	for key, value := range previous {
+		if !strings.HasPrefix(key, AnnotationPrefix) {
+			customMetadata[key] = value
+		}
+	}
+	if err := v.writeMetadata(ctx, name, customMetadata); err != nil {
+		return err
+	}
+
+	if _, err := v.do(ctx, http.MethodPost, v.url("data", name), body, nil); err != nil {
+		var restoreErr error
+		if status == http.StatusNotFound {
+			restoreErr = v.Delete(ctx, name)
+		} else {
+			restoreErr = v.writeMetadata(ctx, name, previous)
+		}
+		if restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("error restoring metadata of vault secret %s: %w", name, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (v *Vault) writeMetadata(ctx context.Context, name string, customMetadata map[string]string) error {
+	_, err := v.do(ctx, http.MethodPost, v.url("metadata", name), map[string]interface{}{"custom_metadata": customMetadata}, nil)
+	return err
+}
+
+// Delete removes all versions and metadata of the secret
+func (v *Vault) Delete(ctx context.Context, name string) error {
+	_, err := v.do(ctx, http.MethodDelete, v.url("metadata", name), nil, nil)
+	return err
+}
+
+func (v *Vault) url(kind, name string) string {
+	return fmt.Sprintf("%s/v1/%s", v.config.Address, path.Join(v.config.Mount, kind, v.config.PathPrefix, name))
+}
+
+// do sends the request with the client token, 404 status is returned without error. Client token of Kubernetes auth
+// method login is renewed by a new login once the request is denied with it, e.g. after the token expired.
+func (v *Vault) do(ctx context.Context, method, url string, body, result interface{}) (int, error) {
+	token, err := v.clientToken(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := v.send(ctx, token, method, url, body, result)
+	if err == nil && resp.StatusCode() == http.StatusForbidden && v.config.Token == "" {
+		logger.InfoC(ctx, "Vault denied %s %s with client token of role '%s', logging in again", method, url, v.config.KubernetesRole)
+		v.expireToken(token)
+		if token, err = v.clientToken(ctx); err != nil {
+			return 0, err
+		}
+		resp, err = v.send(ctx, token, method, url, body, result)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error sending %s %s request to vault: %w", method, url, err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return resp.StatusCode(), nil
+	}
+	if resp.IsError() {
+		return resp.StatusCode(), vaultError(method, url, resp)
+	}
+	return resp.StatusCode(), nil
+}
+
+func (v *Vault) send(ctx context.Context, token, method, url string, body, result interface{}) (*resty.Response, error) {
+	request := v.request(ctx).SetHeader("X-Vault-Token", token)
+	if body != nil {
+		request.SetBody(body)
+	}
+	if result != nil {
+		request.SetResult(result)
+	}
+	return request.Execute(method, url)
+}
+
+func (v *Vault) request(ctx context.Context) *resty.Request {
+	request := v.httpClient.R().SetContext(ctx).SetError(&vaultErrors{})
+	if v.config.Namespace != "" {
+		request.SetHeader("X-Vault-Namespace", v.config.Namespace)
+	}
+	return request
+}
+
+// expireToken makes the next clientToken call log in again unless the token was already renewed
+func (v *Vault) expireToken(token string) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.token == token {
+		v.token = ""
+	}
+}
+
+// clientToken returns the configured token or the token of the last Kubernetes auth method login, logging in
+// if there is none
+func (v *Vault) clientToken(ctx context.Context) (string, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.token != "" {
+		return v.token, nil
+	}
+
+	jwt, err := os.ReadFile(v.config.KubernetesJWTFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading service account token for vault login: %w", err)
+	}
+	var login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	url := fmt.Sprintf("%s/v1/auth/%s/login", v.config.Address, strings.Trim(v.config.KubernetesAuthPath, "/"))
+	resp, err := v.request(ctx).
+		SetBody(map[string]string{"role": v.config.KubernetesRole, "jwt": strings.TrimSpace(string(jwt))}).
+		SetResult(&login).
+		Post(url)
+	if err != nil {
+		return "", fmt.Errorf("error sending vault login request: %w", err)
+	}
+	if resp.IsError() {
+		return "", vaultError(http.MethodPost, url, resp)
+	}
+	if login.Auth.ClientToken == "" {
+		return "", fmt.Errorf("vault login as role '%s' returned no client token", v.config.KubernetesRole)
+	}
+	v.token = login.Auth.ClientToken
+	return v.token, nil
+}
+
+func vaultError(method, url string, resp *resty.Response) error {
+	message := resp.String()
+	if errs, ok := resp.Error().(*vaultErrors); ok && len(errs.Errors) > 0 {
+		message = strings.Join(errs.Errors, "; ")
+	}
+	return fmt.Errorf("vault %s %s failed with status %d: %s", method, url, resp.StatusCode(), message)
+}
@@ -0,0 +1,238 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	rootToken = "root-token"
+	loginJWT  = "service-account-jwt"
+)
+
+type vaultSecret struct {
+	created        time.Time
+	versions       []map[string]string
+	customMetadata map[string]string
+}
+
+// fakeVault keeps secrets in memory and serves a subset of Vault KV v2 API mounted at `secret'
+// and Kubernetes auth method mounted at `kubernetes'
+type fakeVault struct {
+	mutex     sync.Mutex
+	secrets   map[string]*vaultSecret
+	logins    int
+	namespace string
+	// loginToken is the client token issued by the last login until it is revoked
+	loginToken string
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, string) {
+	fake := &fakeVault{secrets: map[string]*vaultSecret{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server.URL
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.namespace = r.Header.Get("X-Vault-Namespace")
+
+	if r.URL.Path == "/v1/auth/kubernetes/login" {
+		var login map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login["jwt"] != loginJWT || login["role"] != "core" {
+			f.fail(w, http.StatusForbidden, "permission denied")
+			return
+		}
+		f.logins++
+		f.loginToken = fmt.Sprintf("login-token-%d", f.logins)
+		f.respond(w, map[string]interface{}{"auth": map[string]string{"client_token": f.loginToken}})
+		return
+	}
+	if token := r.Header.Get("X-Vault-Token"); token != rootToken && (token == "" || token != f.loginToken) {
+		f.fail(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	kind, name, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v1/secret/"), "/")
+	if !found {
+		f.fail(w, http.StatusNotFound, "")
+		return
+	}
+	secret := f.secrets[name]
+	switch {
+	case kind == "data" && r.Method == http.MethodGet:
+		if secret == nil || len(secret.versions) == 0 {
+			f.fail(w, http.StatusNotFound, "")
+			return
+		}
+		f.respond(w, map[string]interface{}{"data": map[string]interface{}{"data": secret.versions[len(secret.versions)-1]}})
+	case kind == "data" && r.Method == http.MethodPost:
+		var body struct {
+			Data    map[string]string `json:"data"`
+			Options map[string]int    `json:"options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if secret == nil {
+			secret = &vaultSecret{created: time.Now().UTC()}
+			f.secrets[name] = secret
+		}
+		if cas, ok := body.Options["cas"]; ok && cas != len(secret.versions) {
+			f.fail(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		secret.versions = append(secret.versions, body.Data)
+		f.respond(w, map[string]interface{}{"data": map[string]int{"version": len(secret.versions)}})
+	case kind == "metadata" && r.Method == http.MethodGet:
+		if secret == nil {
+			f.fail(w, http.StatusNotFound, "")
+			return
+		}
+		f.respond(w, map[string]interface{}{"data": map[string]interface{}{
+			"created_time":    secret.created,
+			"current_version": len(secret.versions),
+			"custom_metadata": secret.customMetadata,
+		}})
+	case kind == "metadata" && r.Method == http.MethodPost:
+		var body struct {
+			CustomMetadata map[string]string `json:"custom_metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if secret == nil {
+			secret = &vaultSecret{created: time.Now().UTC()}
+			f.secrets[name] = secret
+		}
+		secret.customMetadata = body.CustomMetadata
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "metadata" && r.Method == http.MethodDelete:
+		delete(f.secrets, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "unsupported request")
+	}
+}
+
+func (f *fakeVault) respond(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeVault) fail(w http.ResponseWriter, status int, message string) {
+	errs := []string{}
+	if message != "" {
+		errs = append(errs, message)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(vaultErrors{Errors: errs})
+}
+
+func TestVault_WriteRead(t *testing.T) {
+	tokenMaxAge := AnnotationPrefix + "token-max-age"
+	fake, address := newFakeVault(t)
+	vault := NewVault(resty.New(), VaultConfig{Address: address + "/", Token: rootToken, Namespace: "team", PathPrefix: "core-bootstrap/core"})
+	ctx := context.Background()
+
+	missing, err := vault.Read(ctx, "consul-token")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, vault.Write(ctx, "consul-token", Credentials{
+		Data:        map[string][]byte{"token": []byte("first")},
+		Annotations: map[string]string{tokenMaxAge: "720h", "ignored": "value"},
+	}))
+	stored, err := vault.Read(ctx, "consul-token")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, map[string][]byte{"token": []byte("first")}, stored.Data)
+	assert.Equal(t, map[string]string{tokenMaxAge: "720h"}, stored.Annotations, "annotations of others are not written")
+	assert.Equal(t, "1", stored.Version)
+	assert.False(t, stored.Created.IsZero())
+	assert.Contains(t, fake.secrets, "core-bootstrap/core/consul-token")
+	fake.secrets["core-bootstrap/core/consul-token"].customMetadata["owner"] = "team"
+	assert.Equal(t, "team", fake.namespace)
+
+	require.NoError(t, SaveOver(ctx, vault, stored, "consul-token", map[string][]byte{"token": []byte("second")}, nil))
+	updated, err := vault.Read(ctx, "consul-token")
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(updated.Data["token"]))
+	assert.Equal(t, "2", updated.Version)
+	assert.Equal(t, map[string]string{
+		tokenMaxAge:           "720h",
+		"owner":               "team",
+		ContentHashAnnotation: ContentHash(map[string][]byte{"token": []byte("second")}),
+		ContentKeysAnnotation: "token",
+	}, updated.Annotations, "annotations are kept")
+	assert.Empty(t, DriftDetail(updated), "content hash is kept in custom metadata")
+
+	err = SaveOver(ctx, vault, stored, "consul-token", map[string][]byte{"token": []byte("stale")}, map[string]string{tokenMaxAge: "1h"})
+	assert.ErrorContains(t, err, "check-and-set parameter did not match the current version")
+	notUpdated, err := vault.Read(ctx, "consul-token")
+	require.NoError(t, err)
+	assert.Equal(t, updated, notUpdated, "metadata is restored if the version is not stored")
+
+	require.NoError(t, vault.Delete(ctx, "consul-token"))
+	deleted, err := vault.Read(ctx, "consul-token")
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+}
+
+func TestVault_KubernetesLogin(t *testing.T) {
+	fake, address := newFakeVault(t)
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwtFile, []byte(loginJWT+"\n"), 0o600))
+	vault := NewVault(resty.New(), VaultConfig{Address: address, KubernetesRole: "core", KubernetesJWTFile: jwtFile})
+	ctx := context.Background()
+
+	require.NoError(t, vault.Write(ctx, "maas", Credentials{Data: map[string][]byte{"username": []byte("agent")}}))
+	stored, err := vault.Read(ctx, "maas")
+	require.NoError(t, err)
+	assert.Equal(t, "agent", string(stored.Data["username"]))
+	assert.Equal(t, 1, fake.logins, "client token is reused")
+
+	fake.loginToken = ""
+	stored, err = vault.Read(ctx, "maas")
+	require.NoError(t, err, "revoked client token is renewed")
+	assert.Equal(t, "agent", string(stored.Data["username"]))
+	assert.Equal(t, 2, fake.logins)
+
+	denied := NewVault(resty.New(), VaultConfig{Address: address, KubernetesRole: "other", KubernetesJWTFile: jwtFile})
+	_, err = denied.Read(ctx, "maas")
+	assert.ErrorContains(t, err, "failed with status 403: permission denied")
+}
+
+func TestVault_Errors(t *testing.T) {
+	fake, address := newFakeVault(t)
+	vault := NewVault(resty.New(), VaultConfig{Address: address, Token: "revoked"})
+
+	_, err := vault.Read(context.Background(), "maas")
+	assert.ErrorContains(t, err, "permission denied")
+
+	vault = NewVault(resty.New(), VaultConfig{Address: address, Token: rootToken})
+	err = vault.Write(context.Background(), "maas", Credentials{Version: "latest"})
+	assert.ErrorContains(t, err, "invalid version 'latest'")
+
+	err = vault.Write(context.Background(), "maas", Credentials{Data: map[string][]byte{"username": []byte("agent")}, Version: "3"})
+	assert.ErrorContains(t, err, "check-and-set parameter did not match the current version")
+	assert.Empty(t, fake.secrets, "metadata of a new secret is deleted if the version is not stored")
+}
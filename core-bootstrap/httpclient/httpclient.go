@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+)
+
+// Endpoints are admin APIs called by tasks, each has its own client profile and TLS settings
+const (
+	Consul = "consul"
+	DBaaS  = "dbaas"
+	MaaS   = "maas"
+	Vault  = "vault"
+)
+
+var logger = logging.GetLogger("httpclient")
+
+// Profile defines timeout of a single call and retries of the endpoint client. Calls are retried only for
+// idempotent methods after connection errors or 5xx responses, see Retryable.
+type Profile struct {
+	Timeout          time.Duration
+	RetryCount       int
+	RetryWaitTime    time.Duration
+	RetryMaxWaitTime time.Duration
+}
+
+var DefaultProfile = Profile{
+	Timeout:          10 * time.Second,
+	RetryCount:       3,
+	RetryWaitTime:    2 * time.Second,
+	RetryMaxWaitTime: 10 * time.Second,
+}
+
+// ReadProfile reads `<ENDPOINT>_HTTP_TIMEOUT', `<ENDPOINT>_HTTP_RETRY_COUNT', `<ENDPOINT>_HTTP_RETRY_WAIT_TIME'
+// and `<ENDPOINT>_HTTP_RETRY_MAX_WAIT_TIME' values, values which are not set are taken from DefaultProfile
+func ReadProfile(reader *configsource.Reader, endpoint string) Profile {
+	prefix := strings.ToUpper(endpoint) + "_HTTP_"
+	profile := DefaultProfile
+	if timeout := reader.Duration(prefix + "TIMEOUT"); timeout > 0 {
+		profile.Timeout = timeout
+	}
+	profile.RetryCount = reader.NonNegativeInt(prefix+"RETRY_COUNT", DefaultProfile.RetryCount)
+	if wait := reader.Duration(prefix + "RETRY_WAIT_TIME"); wait > 0 {
+		profile.RetryWaitTime = wait
+	}
+	if wait := reader.Duration(prefix + "RETRY_MAX_WAIT_TIME"); wait > 0 {
+		profile.RetryMaxWaitTime = wait
+	}
+	if profile.RetryMaxWaitTime < profile.RetryWaitTime {
+		reader.Fail("%sRETRY_MAX_WAIT_TIME %s is less than %sRETRY_WAIT_TIME %s", prefix, profile.RetryMaxWaitTime, prefix, profile.RetryWaitTime)
+	}
+	return profile
+}
+
+// New returns HTTP client of the endpoint with the profile logging response time of every call.
+// Nil tlsConfig verifies the endpoint by system roots.
+func New(endpoint string, profile Profile, tlsConfig *tls.Config) *resty.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := resty.NewWithClient(&http.Client{Transport: &timingTransport{endpoint: endpoint, next: transport}})
+	client.SetDisableWarn(true)
+
+	client.SetTimeout(profile.Timeout)
+
+	client.SetRetryCount(profile.RetryCount)
+	client.SetRetryWaitTime(profile.RetryWaitTime)
+	client.SetRetryMaxWaitTime(profile.RetryMaxWaitTime)
+	client.AddRetryCondition(Retryable)
+	return client
+}
+
+// Retryable allows retries of idempotent calls failed with connection errors or 5xx responses only,
+// non-idempotent calls like MaaS client registration are never repeated. Check-and-set writes of Consul KV are not
+// repeated either: if the lost response was a success, the repeated write fails on the index it has just changed.
+func Retryable(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !idempotent(resp.Request.Method) || checkAndSet(resp.Request) {
+		return false
+	}
+	return err != nil || resp.StatusCode() >= http.StatusInternalServerError
+}
+
+func checkAndSet(req *resty.Request) bool {
+	if req.QueryParam.Has("cas") {
+		return true
+	}
+	return req.RawRequest != nil && req.RawRequest.URL.Query().Has("cas")
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// timingTransport logs status and response time of every call including retries
+type timingTransport struct {
+	endpoint string
+	next     http.RoundTripper
+}
+
+func (t *timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		logger.WarnC(req.Context(), "%s call %s %s failed in %s: %v", t.endpoint, req.Method, req.URL.Redacted(), elapsed, err)
+		return resp, err
+	}
+	logger.InfoC(req.Context(), "%s call %s %s returned %d in %s", t.endpoint, req.Method, req.URL.Redacted(), resp.StatusCode, elapsed)
+	return resp, nil
+}
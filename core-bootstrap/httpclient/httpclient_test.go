@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fastRetries = Profile{Timeout: 5 * time.Second, RetryCount: 2, RetryWaitTime: time.Millisecond, RetryMaxWaitTime: time.Millisecond}
+
+func countingServer(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestNew_Retries(t *testing.T) {
+	for _, test := range []struct {
+		method string
+		status int
+		calls  int32
+	}{
+		{http.MethodGet, http.StatusServiceUnavailable, 3},
+		{http.MethodPut, http.StatusBadGateway, 3},
+		{http.MethodDelete, http.StatusInternalServerError, 3},
+		{http.MethodPost, http.StatusServiceUnavailable, 1},
+		{http.MethodPatch, http.StatusServiceUnavailable, 1},
+		{http.MethodGet, http.StatusTooManyRequests, 1},
+		{http.MethodGet, http.StatusNotFound, 1},
+		{http.MethodGet, http.StatusOK, 1},
+	} {
+		server, calls := countingServer(t, test.status)
+		resp, err := New(MaaS, fastRetries, nil).R().Execute(test.method, server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, test.status, resp.StatusCode())
+		assert.Equal(t, test.calls, calls.Load(), "%s with %d response", test.method, test.status)
+	}
+}
+
+func TestRetryable_ConnectionErrors(t *testing.T) {
+	server, _ := countingServer(t, http.StatusOK)
+	server.Close()
+
+	client := New(Consul, fastRetries, nil)
+	var attempts atomic.Int32
+	client.AddRetryHook(func(*resty.Response, error) { attempts.Add(1) })
+
+	_, err := client.R().Get(server.URL)
+	assert.Error(t, err)
+	assert.Equal(t, int32(fastRetries.RetryCount+1), attempts.Load(), "GET is retried")
+
+	attempts.Store(0)
+	_, err = client.R().Post(server.URL)
+	assert.Error(t, err)
+	assert.Zero(t, attempts.Load(), "POST is not retried")
+}
+
+func TestReadProfile(t *testing.T) {
+	read := func(values map[string]string) (Profile, error) {
+		reader := configsource.NewReader(func(name string) string { return values[name] })
+		profile := ReadProfile(reader, DBaaS)
+		return profile, reader.Err()
+	}
+
+	profile, err := read(map[string]string{"CONSUL_HTTP_TIMEOUT": "1m"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultProfile, profile, "other endpoint values are ignored")
+
+	profile, err = read(map[string]string{
+		"DBAAS_HTTP_TIMEOUT":             "1m",
+		"DBAAS_HTTP_RETRY_COUNT":         "0",
+		"DBAAS_HTTP_RETRY_WAIT_TIME":     "500ms",
+		"DBAAS_HTTP_RETRY_MAX_WAIT_TIME": "5s",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Profile{Timeout: time.Minute, RetryWaitTime: 500 * time.Millisecond, RetryMaxWaitTime: 5 * time.Second}, profile)
+
+	_, err = read(map[string]string{"DBAAS_HTTP_RETRY_WAIT_TIME": "1m"})
+	assert.ErrorContains(t, err, "DBAAS_HTTP_RETRY_MAX_WAIT_TIME 10s is less than DBAAS_HTTP_RETRY_WAIT_TIME 1m0s")
+
+	_, err = read(map[string]string{"DBAAS_HTTP_RETRY_COUNT": "-1"})
+	assert.ErrorContains(t, err, "DBAAS_HTTP_RETRY_COUNT")
+}
+
+func TestRetryable_CheckAndSet(t *testing.T) {
+	server, calls := countingServer(t, http.StatusBadGateway)
+	client := New(Consul, fastRetries, nil)
+
+	resp, err := client.R().SetQueryParam("cas", "0").Put(server.URL + "/v1/kv/config/key")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode())
+	assert.Equal(t, int32(1), calls.Load(), "PUT with cas is not retried")
+
+	calls.Store(0)
+	_, err = client.R().Delete(server.URL + "/v1/kv/config/key?cas=12")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load(), "DELETE with cas in URL is not retried")
+}
@@ -0,0 +1,141 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+)
+
+const (
+	// CASecretKey is the key of CA bundle in the Secret named by TLS_CA_SECRET
+	CASecretKey = "ca.crt"
+	// CertSecretKey and KeySecretKey are keys of client certificate in the kubernetes.io/tls Secret named by TLS_CERT_SECRET
+	CertSecretKey = "tls.crt"
+	KeySecretKey  = "tls.key"
+)
+
+// TLSConfig configures verification of the endpoint certificate and, optionally, the client certificate for mTLS
+type TLSConfig struct {
+	// CAFile and CASecret provide PEM CA bundles trusted in addition to system roots
+	CAFile   string
+	CASecret string
+	// CertFile and KeyFile or CertSecret provide the client certificate
+	CertFile   string
+	KeyFile    string
+	CertSecret string
+	// InsecureSkipVerify disables verification of the endpoint certificate
+	InsecureSkipVerify bool
+}
+
+// SecretLoader returns data of the Secret with the name
+type SecretLoader func(name string) (map[string][]byte, error)
+
+var tlsValues = []string{"TLS_CA_FILE", "TLS_CA_SECRET", "TLS_CERT_FILE", "TLS_KEY_FILE", "TLS_CERT_SECRET", "TLS_INSECURE_SKIP_VERIFY"}
+
+// ReadTLSConfig reads TLS settings of the endpoint, e.g. `consul': if any of `<ENDPOINT>_TLS_*' values is set,
+// the endpoint values are used, otherwise the common `TLS_*' ones
+func ReadTLSConfig(reader *configsource.Reader, endpoint string) TLSConfig {
+	prefix := ""
+	for _, name := range tlsValues {
+		if reader.Optional(strings.ToUpper(endpoint)+"_"+name) != "" {
+			prefix = strings.ToUpper(endpoint) + "_"
+			break
+		}
+	}
+	config := TLSConfig{
+		CAFile:             reader.Optional(prefix + "TLS_CA_FILE"),
+		CASecret:           reader.Optional(prefix + "TLS_CA_SECRET"),
+		CertFile:           reader.Optional(prefix + "TLS_CERT_FILE"),
+		KeyFile:            reader.Optional(prefix + "TLS_KEY_FILE"),
+		CertSecret:         reader.Optional(prefix + "TLS_CERT_SECRET"),
+		InsecureSkipVerify: reader.Boolean(prefix + "TLS_INSECURE_SKIP_VERIFY"),
+	}
+	if err := config.Validate(); err != nil {
+		reader.Fail("invalid %sTLS_* configuration: %w", prefix, err)
+	}
+	return config
+}
+
+func (c TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("client certificate and key files must be set together")
+	}
+	if c.CertFile != "" && c.CertSecret != "" {
+		return errors.New("client certificate can be set either by files or by secret")
+	}
+	return nil
+}
+
+// Build loads CA bundles and the client certificate, Secrets are read by loadSecret
+func (c TLSConfig) Build(loadSecret SecretLoader) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.InsecureSkipVerify}
+
+	var bundles [][]byte
+	if c.CAFile != "" {
+		bundle, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA bundle: %w", err)
+		}
+		bundles = append(bundles, bundle)
+	}
+	if c.CASecret != "" {
+		bundle, err := secretValue(loadSecret, c.CASecret, CASecretKey)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, bundle)
+	}
+	if len(bundles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, bundle := range bundles {
+			if !pool.AppendCertsFromPEM(bundle) {
+				return nil, errors.New("CA bundle contains no PEM certificates")
+			}
+		}
+		config.RootCAs = pool
+	}
+
+	switch {
+	case c.CertFile != "":
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	case c.CertSecret != "":
+		cert, err := secretValue(loadSecret, c.CertSecret, CertSecretKey)
+		if err != nil {
+			return nil, err
+		}
+		key, err := secretValue(loadSecret, c.CertSecret, KeySecretKey)
+		if err != nil {
+			return nil, err
+		}
+		certificate, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate from secret %s: %w", c.CertSecret, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+func secretValue(loadSecret SecretLoader, name, key string) ([]byte, error) {
+	data, err := loadSecret(name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secret %s: %w", name, err)
+	}
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no key %s", name, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPEM     []byte
+	keyPEM      []byte
+}
+
+func newCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.certificate, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		certPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+type testPKI struct {
+	ca     *testCertificate
+	client *testCertificate
+	server *httptest.Server
+}
+
+// newTestPKI starts HTTPS server with certificate issued by a test CA, the server requires client certificates
+// issued by the CA if mTLS is true
+func newTestPKI(t *testing.T, mTLS bool) *testPKI {
+	ca := newCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	serverCert := newCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "consul"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "core-bootstrap"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serverPair, err := tls.X509KeyPair(serverCert.certPEM, serverCert.keyPEM)
+	require.NoError(t, err)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{serverPair}}
+	if mTLS {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.certificate)
+		server.TLS.ClientCAs = pool
+		server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return &testPKI{ca: ca, client: clientCert, server: server}
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	return file
+}
+
+func (p *testPKI) call(t *testing.T, config TLSConfig, secrets map[string]map[string][]byte) error {
+	tlsConfig, err := config.Build(func(name string) (map[string][]byte, error) {
+		data, ok := secrets[name]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return data, nil
+	})
+	require.NoError(t, err)
+	client := New(Consul, Profile{Timeout: 5 * time.Second}, tlsConfig)
+	_, err = client.R().Get(p.server.URL)
+	return err
+}
+
+func TestTLSConfig_Build(t *testing.T) {
+	pki := newTestPKI(t, false)
+
+	err := pki.call(t, TLSConfig{}, nil)
+	assert.ErrorContains(t, err, "certificate signed by unknown authority", "system roots only")
+
+	assert.NoError(t, pki.call(t, TLSConfig{CAFile: writeFile(t, "ca.crt", pki.ca.certPEM)}, nil))
+	assert.NoError(t, pki.call(t, TLSConfig{CASecret: "consul-ca"}, map[string]map[string][]byte{
+		"consul-ca": {CASecretKey: pki.ca.certPEM},
+	}))
+	assert.NoError(t, pki.call(t, TLSConfig{InsecureSkipVerify: true}, nil))
+}
+
+func TestTLSConfig_BuildMutualTLS(t *testing.T) {
+	pki := newTestPKI(t, true)
+	caFile := writeFile(t, "ca.crt", pki.ca.certPEM)
+
+	assert.Error(t, pki.call(t, TLSConfig{CAFile: caFile}, nil), "no client certificate")
+
+	assert.NoError(t, pki.call(t, TLSConfig{
+		CAFile:   caFile,
+		CertFile: writeFile(t, "tls.crt", pki.client.certPEM),
+		KeyFile:  writeFile(t, "tls.key", pki.client.keyPEM),
+	}, nil))
+	assert.NoError(t, pki.call(t, TLSConfig{CAFile: caFile, CertSecret: "client"}, map[string]map[string][]byte{
+		"client": {CertSecretKey: pki.client.certPEM, KeySecretKey: pki.client.keyPEM},
+	}))
+}
+
+func TestTLSConfig_BuildErrors(t *testing.T) {
+	noSecrets := func(name string) (map[string][]byte, error) { return nil, errors.New("not found") }
+
+	_, err := TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing")}.Build(noSecrets)
+	assert.ErrorContains(t, err, "error reading CA bundle")
+
+	_, err = TLSConfig{CAFile: writeFile(t, "ca.crt", []byte("not a certificate"))}.Build(noSecrets)
+	assert.EqualError(t, err, "CA bundle contains no PEM certificates")
+
+	_, err = TLSConfig{CASecret: "consul-ca"}.Build(noSecrets)
+	assert.EqualError(t, err, "error reading secret consul-ca: not found")
+
+	_, err = TLSConfig{CertSecret: "client"}.Build(func(string) (map[string][]byte, error) {
+		return map[string][]byte{CertSecretKey: []byte("cert")}, nil
+	})
+	assert.EqualError(t, err, "secret client has no key tls.key")
+}
+
+func TestReadTLSConfig(t *testing.T) {
+	read := func(values map[string]string, endpoint string) (TLSConfig, error) {
+		reader := configsource.NewReader(func(name string) string { return values[name] })
+		config := ReadTLSConfig(reader, endpoint)
+		return config, reader.Err()
+	}
+	values := map[string]string{
+		"TLS_CA_SECRET":                 "cluster-ca",
+		"CONSUL_TLS_CA_FILE":            "/etc/consul/ca.crt",
+		"CONSUL_TLS_CERT_SECRET":        "consul-client",
+		"MAAS_TLS_INSECURE_SKIP_VERIFY": "true",
+	}
+
+	config, err := read(values, "dbaas")
+	require.NoError(t, err)
+	assert.Equal(t, TLSConfig{CASecret: "cluster-ca"}, config, "common settings")
+
+	config, err = read(values, "consul")
+	require.NoError(t, err)
+	assert.Equal(t, TLSConfig{CAFile: "/etc/consul/ca.crt", CertSecret: "consul-client"}, config, "endpoint settings replace common ones")
+
+	config, err = read(values, "maas")
+	require.NoError(t, err)
+	assert.Equal(t, TLSConfig{InsecureSkipVerify: true}, config)
+
+	_, err = read(map[string]string{"VAULT_TLS_CERT_FILE": "/etc/tls/tls.crt"}, "vault")
+	assert.ErrorContains(t, err, "invalid VAULT_TLS_* configuration: client certificate and key files must be set together")
+
+	_, err = read(map[string]string{"TLS_CERT_FILE": "tls.crt", "TLS_KEY_FILE": "tls.key", "TLS_CERT_SECRET": "client"}, "vault")
+	assert.ErrorContains(t, err, "client certificate can be set either by files or by secret")
+}
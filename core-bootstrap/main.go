@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"os"
 
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	k8ssource "github.com/netcracker/core-bootstrap/v2/configsource/kubernetes"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/taskmanager/factory"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager/journal"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager/report"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 )
@@ -23,13 +29,115 @@ func main() {
 		os.Exit(exitCode)
 	})
 
-	var isPostDeployPhase bool
+	var isPostDeployPhase, isDryRun, isForceRerun, isValidateOnly bool
+	var phaseName, reportConfigMap, reportFile, pipelineFile string
+	var configDir, configSecret, configConfigMap string
 	flag.BoolVar(&isPostDeployPhase, "post", false, "postdeploy")
+	flag.StringVar(&phaseName, "phase", "", "phase to run: predeploy, postdeploy or uninstall; takes precedence over -post")
+	flag.BoolVar(&isDryRun, "dry-run", false, "print planned changes without applying them")
+	flag.BoolVar(&isValidateOnly, "validate-only", false, "check configuration of all tasks of all phases, or of the phase selected by -phase or -post, and exit")
+	flag.BoolVar(&isForceRerun, "force", false, "execute all tasks ignoring execution journal of previous failed run")
+	flag.StringVar(&reportConfigMap, "report-configmap", "", "name of ConfigMap to store execution report in")
+	flag.StringVar(&reportFile, "report-file", "", "path of file to write execution report to, e.g. /dev/termination-log")
+	flag.StringVar(&pipelineFile, "pipeline", "", "path of YAML or JSON pipeline definition to use instead of default tasks")
+	flag.StringVar(&configDir, "config-dir", "/etc/secrets", "directory with mounted configuration files named after the values")
+	flag.StringVar(&configSecret, "config-secret", "", "name of Secret with configuration values")
+	flag.StringVar(&configConfigMap, "config-configmap", "", "name of ConfigMap with configuration values")
 	flag.Parse()
 
-	taskManager := factory.CreateDefaultManager()
+	phase := taskmanager.PreDeployPhase
+	if isPostDeployPhase {
+		phase = taskmanager.PostDeployPhase
+	}
+	if phaseName != "" {
+		var err error
+		if phase, err = taskmanager.ParsePhase(phaseName); err != nil {
+			logger.PanicC(ctx, "Invalid -phase flag: %s", err)
+		}
+	}
+
+	namespace := os.Getenv("NAMESPACE")
+	accessor, err := configAccessor(ctx, namespace, configDir, configSecret, configConfigMap)
+	if err != nil {
+		logger.PanicC(ctx, "Error loading configuration sources: %s", err)
+	}
+	reportSinks := []taskmanager.ReportSink{report.NewStdoutSink()}
+	if reportConfigMap != "" {
+		reportSinks = append(reportSinks, report.NewConfigMapSink(namespace, reportConfigMap))
+	}
+	if reportFile != "" {
+		reportSinks = append(reportSinks, report.NewFileSink(reportFile))
+	}
+
+	options := []taskmanager.Option{
+		taskmanager.WithJournal(journal.NewConfigMapJournal(namespace, journal.DefaultConfigMapName)),
+		taskmanager.WithForceRerun(isForceRerun),
+		taskmanager.WithReportSinks(reportSinks...),
+		taskmanager.WithAccessor(accessor.Get),
+	}
+	var taskManager *taskmanager.TaskManager
+	if pipelineFile != "" {
+		if taskManager, err = factory.CreatePipelineManager(pipelineFile, options...); err != nil {
+			logger.PanicC(ctx, "Error loading pipeline: %s", err)
+		}
+	} else {
+		taskManager = factory.CreateDefaultManager(options...)
+	}
+	if err := taskManager.Validate(); err != nil {
+		logger.PanicC(ctx, "Invalid tasks configuration: %s", err)
+	}
 
-	if err := taskManager.Execute(ctx, isPostDeployPhase); err != nil {
-		logger.PanicC(ctx, "Error during execution: %s", err)
+	if isValidateOnly {
+		validate := taskManager.ValidateAllConfigurations
+		if isPostDeployPhase || phaseName != "" {
+			validate = func(ctx context.Context) error { return taskManager.ValidateConfiguration(ctx, phase) }
+		}
+		if err := validate(ctx); err != nil {
+			logger.ErrorC(ctx, "Invalid configuration:\n%s", err)
+			os.Exit(1)
+		}
+		logger.InfoC(ctx, "Configuration is valid")
+		return
+	}
+
+	if isDryRun {
+		plans, err := taskManager.PlanPhase(ctx, phase)
+		if err != nil {
+			logger.PanicC(ctx, "Error during plan: %s", err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plans); err != nil {
+			logger.PanicC(ctx, "Error printing plan: %s", err)
+		}
+		return
+	}
+
+	// error is already logged and included in the report, which must stay the last line of the output
+	if err := taskManager.ExecutePhase(ctx, phase); err != nil {
+		os.Exit(1)
+	}
+}
+
+// configAccessor layers configuration sources with precedence: mounted files, Secret, ConfigMap, environment variables
+func configAccessor(ctx context.Context, namespace, dir, secret, configMap string) (*configsource.Layered, error) {
+	var sources []configsource.Source
+	if dir != "" {
+		sources = append(sources, configsource.NewFileSource(dir))
+	}
+	if secret != "" {
+		source, err := k8ssource.NewSecretSource(ctx, namespace, secret)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	if configMap != "" {
+		source, err := k8ssource.NewConfigMapSource(ctx, namespace, configMap)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
 	}
+	return configsource.NewLayered(append(sources, configsource.NewEnvSource())...), nil
 }
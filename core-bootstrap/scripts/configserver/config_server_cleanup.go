@@ -0,0 +1,38 @@
+package configserver
+
+import (
+	"context"
+
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// Cleanup is the uninstall task removing config-server token, role, policies and token secret
+type Cleanup struct {
+	Configurer
+}
+
+func NewCleanup(consulConfigurer *consul.Configurer) *Cleanup {
+	return &Cleanup{Configurer: Configurer{consulConfigurer: consulConfigurer}}
+}
+
+func (c *Cleanup) Execute(ctx context.Context) error {
+	if !c.consulConfigurer.Enabled {
+		logger.InfoC(ctx, "CONSUL_ENABLED is not set. Skip consul cleanup")
+		return nil
+	}
+	logger.InfoC(ctx, "*** Starting config_server_consul cleanup ***")
+	if err := c.consulConfigurer.RemoveConsulPoliciesAndToken(ctx, c.secretName, c.roleName(), c.requiredPolicies()); err != nil {
+		return utils.LogError(logger, ctx, "error removing config-server access to consul: %w", err)
+	}
+	logger.InfoC(ctx, "### Finished config_server_consul cleanup ***")
+	return nil
+}
+
+func (c *Cleanup) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	if !c.consulConfigurer.Enabled {
+		return nil, nil
+	}
+	return c.consulConfigurer.PlanConsulRemoval(ctx, c.secretName, c.roleName(), c.requiredPolicies())
+}
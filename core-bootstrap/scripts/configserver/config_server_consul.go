@@ -3,12 +3,15 @@ package configserver
 import (
 	"context"
 	"fmt"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
 	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 )
 
 const (
+	TaskName                    = "configserver"
 	ConfigServerConsulTokenName = "config-server-consul-token"
 )
 
@@ -18,17 +21,28 @@ type Configurer struct {
 	Namespace        string
 	consulConfigurer *consul.Configurer
 	secretName       string
+	undoLog          utils.UndoLog
 }
 
 func New(consulConfigurer *consul.Configurer) *Configurer {
 	return &Configurer{consulConfigurer: consulConfigurer}
 }
 
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
+// DependsOn makes config-server access configured only after consul task
+func (c *Configurer) DependsOn() []string {
+	return []string{consul.TaskName}
+}
+
 func (c *Configurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
 	c.secretName = ConfigServerConsulTokenName
 
-	return nil
+	return reader.Err()
 }
 
 func (c *Configurer) Execute(ctx context.Context) error {
@@ -43,9 +57,37 @@ func (c *Configurer) Execute(ctx context.Context) error {
 	return nil
 }
 
+// Rollback restores consul policies, role, token and token secret of config-server changed by Execute
+func (c *Configurer) Rollback(ctx context.Context) error {
+	return c.undoLog.Rollback(ctx)
+}
+
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	if !c.consulConfigurer.Enabled {
+		return nil, nil
+	}
+	return c.consulConfigurer.PlanConsulPoliciesAndToken(ctx, c.secretName, c.roleName(), c.requiredPolicies())
+}
+
 func (c *Configurer) configureConsulAccess(ctx context.Context) error {
 	logger.InfoC(ctx, "*** Starting config_server_consul ***")
 
+	err := c.consulConfigurer.CheckAndCreateConsulPoliciesAndToken(ctx, &c.undoLog, c.secretName, c.roleName(), c.requiredPolicies())
+	if err != nil {
+		return utils.LogError(logger, ctx, "error CheckAndCreateConsulPoliciesAndToken for config server: %w", err)
+	}
+
+	logger.InfoC(ctx, "### Finished config_server_consul ***")
+	return nil
+}
+
+// roleName is the name of consul role attached to config-server token
+func (c *Configurer) roleName() string {
+	return fmt.Sprintf("%s_config-server", c.Namespace)
+}
+
+// requiredPolicies returns policies of config-server role
+func (c *Configurer) requiredPolicies() []consul.Policy {
 	policyConfigWrite := consul.Policy{
 		Name:        fmt.Sprintf("%s_config-edit", c.Namespace),
 		Description: "Policy for configs write",
@@ -58,13 +100,5 @@ func (c *Configurer) configureConsulAccess(ctx context.Context) error {
 		Rules:       fmt.Sprintf(`key_prefix "logging/%s/config-server" { policy = "read" }`, c.Namespace),
 	}
 
-	requiredPolicies := []consul.Policy{policyLoggingRead, policyConfigWrite}
-
-	err := c.consulConfigurer.CheckAndCreateConsulPoliciesAndToken(ctx, c.secretName, requiredPolicies, policyConfigWrite.Name)
-	if err != nil {
-		return utils.LogError(logger, ctx, "error CheckAndCreateConsulPoliciesAndToken for config server: %w", err)
-	}
-
-	logger.InfoC(ctx, "### Finished config_server_consul ***")
-	return nil
+	return []consul.Policy{policyLoggingRead, policyConfigWrite}
 }
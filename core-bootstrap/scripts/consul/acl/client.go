@@ -0,0 +1,162 @@
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	// ErrNotFound matches errors of requests to missing ACL objects
+	ErrNotFound = errors.New("consul ACL object not found")
+	// ErrForbidden matches errors of requests rejected because of insufficient token permissions
+	ErrForbidden = errors.New("consul ACL permission denied")
+	// ErrServer matches errors caused by Consul server failures
+	ErrServer = errors.New("consul server error")
+)
+
+// Error is returned for every non-200 response of Consul, use errors.Is with ErrNotFound, ErrForbidden or ErrServer to classify it
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("consul %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		// Consul responds with 403 `ACL not found' to lookups of missing tokens and policies by name
+		return e.StatusCode == http.StatusNotFound || (e.StatusCode == http.StatusForbidden && strings.Contains(e.Body, "ACL not found"))
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden && !strings.Contains(e.Body, "ACL not found")
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// QueryOptions control blocking list queries, zero value makes a regular query
+type QueryOptions struct {
+	// WaitIndex makes Consul hold the response until the index is exceeded or WaitTime expires
+	WaitIndex uint64
+	WaitTime  time.Duration
+}
+
+// QueryMeta carries the X-Consul-Index of the response to be used as WaitIndex of the next query
+type QueryMeta struct {
+	LastIndex uint64
+}
+
+// Client calls Consul ACL HTTP API with the token it was created with
+type Client struct {
+	http      *resty.Client
+	address   string
+	token     string
+	namespace string
+	partition string
+}
+
+type Option func(*Client)
+
+// WithNamespace scopes all requests to the Consul Enterprise namespace
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		c.namespace = namespace
+	}
+}
+
+// WithPartition scopes all requests to the Consul Enterprise admin partition
+func WithPartition(partition string) Option {
+	return func(c *Client) {
+		c.partition = partition
+	}
+}
+
+func NewClient(httpClient *resty.Client, address, token string, options ...Option) *Client {
+	c := &Client{http: httpClient, address: strings.TrimRight(address, "/"), token: token}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	token  string
+	body   any
+	result any
+}
+
+func (c *Client) do(ctx context.Context, r request) (QueryMeta, error) {
+	token := r.token
+	if token == "" {
+		token = c.token
+	}
+	req := c.http.R().
+		SetContext(ctx).
+		SetHeader("X-Consul-Token", token)
+	if r.body != nil {
+		req.SetHeader("Content-Type", "application/json").SetBody(r.body)
+	}
+	if len(r.query) > 0 {
+		req.SetQueryParamsFromValues(r.query)
+	}
+	if c.namespace != "" {
+		req.SetQueryParam("ns", c.namespace)
+	}
+	if c.partition != "" {
+		req.SetQueryParam("partition", c.partition)
+	}
+
+	resp, err := req.Execute(r.method, c.address+r.path)
+	if err != nil {
+		return QueryMeta{}, fmt.Errorf("consul %s %s failed: %w", r.method, r.path, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return QueryMeta{}, &Error{StatusCode: resp.StatusCode(), Method: r.method, Path: r.path, Body: strings.TrimSpace(resp.String())}
+	}
+
+	var meta QueryMeta
+	if index := resp.Header().Get("X-Consul-Index"); index != "" {
+		if meta.LastIndex, err = strconv.ParseUint(index, 10, 64); err != nil {
+			return QueryMeta{}, fmt.Errorf("consul %s %s returned invalid X-Consul-Index '%s': %w", r.method, r.path, index, err)
+		}
+	}
+	if r.result != nil {
+		if err := json.Unmarshal(resp.Body(), r.result); err != nil {
+			return QueryMeta{}, fmt.Errorf("failed to decode response of consul %s %s: %w", r.method, r.path, err)
+		}
+	}
+	return meta, nil
+}
+
+func (o *QueryOptions) values(query url.Values) url.Values {
+	if query == nil {
+		query = url.Values{}
+	}
+	if o == nil {
+		return query
+	}
+	if o.WaitIndex > 0 {
+		query.Set("index", strconv.FormatUint(o.WaitIndex, 10))
+	}
+	if o.WaitTime > 0 {
+		query.Set("wait", fmt.Sprintf("%dms", o.WaitTime.Milliseconds()))
+	}
+	return query
+}
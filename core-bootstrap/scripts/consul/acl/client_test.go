@@ -0,0 +1,206 @@
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminToken = "admin-secret"
+
+// fakeConsul keeps policies and tokens in memory and serves a subset of Consul ACL API
+type fakeConsul struct {
+	mutex    sync.Mutex
+	index    uint64
+	policies map[string]Policy
+	tokens   map[string]Token
+	queries  []string
+}
+
+func newFakeConsul(t *testing.T) (*fakeConsul, *Client) {
+	fake := &fakeConsul{policies: map[string]Policy{}, tokens: map[string]Token{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewClient(resty.New(), server.URL+"/", adminToken)
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.queries = append(f.queries, r.URL.RawQuery)
+
+	token := r.Header.Get("X-Consul-Token")
+	if r.URL.Path == "/v1/acl/token/self" {
+		for _, existing := range f.tokens {
+			if existing.SecretID == token {
+				f.respond(w, existing)
+				return
+			}
+		}
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+	if token != adminToken {
+		http.Error(w, "Permission denied: token lacks acl:write", http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/acl/")
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "policy/name/"):
+		name := strings.TrimPrefix(path, "policy/name/")
+		for _, policy := range f.policies {
+			if policy.Name == name {
+				f.respond(w, policy)
+				return
+			}
+		}
+		http.Error(w, "ACL not found", http.StatusForbidden)
+	case r.Method == http.MethodPut && path == "policy":
+		var policy Policy
+		_ = json.NewDecoder(r.Body).Decode(&policy)
+		f.index++
+		policy.ID = fmt.Sprintf("policy-%d", f.index)
+		policy.CreateIndex, policy.ModifyIndex = f.index, f.index
+		f.policies[policy.ID] = policy
+		f.respond(w, policy)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "policy/"):
+		var policy Policy
+		_ = json.NewDecoder(r.Body).Decode(&policy)
+		previous, ok := f.policies[strings.TrimPrefix(path, "policy/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		f.index++
+		policy.ID, policy.CreateIndex, policy.ModifyIndex = previous.ID, previous.CreateIndex, f.index
+		f.policies[policy.ID] = policy
+		f.respond(w, policy)
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "policy/"):
+		delete(f.policies, strings.TrimPrefix(path, "policy/"))
+		f.index++
+		_, _ = w.Write([]byte("true"))
+	case r.Method == http.MethodPut && path == "token":
+		var token Token
+		_ = json.NewDecoder(r.Body).Decode(&token)
+		f.index++
+		token.AccessorID, token.SecretID = fmt.Sprintf("accessor-%d", f.index), fmt.Sprintf("secret-%d", f.index)
+		f.tokens[token.AccessorID] = token
+		f.respond(w, token)
+	case r.Method == http.MethodGet && path == "tokens":
+		var tokens []Token
+		for _, token := range f.tokens {
+			for _, policy := range token.Policies {
+				if policy.ID == r.URL.Query().Get("policy") {
+					token.SecretID = ""
+					tokens = append(tokens, token)
+				}
+			}
+		}
+		f.respond(w, tokens)
+	case path == "roles":
+		http.Error(w, "rpc error: leader unavailable", http.StatusInternalServerError)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeConsul) respond(w http.ResponseWriter, body any) {
+	w.Header().Set("X-Consul-Index", fmt.Sprint(f.index))
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestPolicyLifecycle(t *testing.T) {
+	_, client := newFakeConsul(t)
+	ctx := context.Background()
+
+	_, err := client.ReadPolicyByName(ctx, "ns_config-edit")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrForbidden)
+
+	created, err := client.CreatePolicy(ctx, &Policy{Name: "ns_config-edit", Rules: `key_prefix "config/ns/" { policy = "write" }`})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+
+	created.Description = "updated"
+	updated, err := client.UpdatePolicy(ctx, created)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Greater(t, updated.ModifyIndex, created.ModifyIndex)
+
+	read, err := client.ReadPolicyByName(ctx, "ns_config-edit")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", read.Description)
+
+	require.NoError(t, client.DeletePolicy(ctx, created.ID))
+	_, err = client.ReadPolicyByName(ctx, "ns_config-edit")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTokens(t *testing.T) {
+	fake, client := newFakeConsul(t)
+	ctx := context.Background()
+	policy, err := client.CreatePolicy(ctx, &Policy{Name: "ns_config-edit"})
+	require.NoError(t, err)
+
+	created, err := client.CreateToken(ctx, &Token{Description: "bootstrap token", Policies: []Link{{ID: policy.ID, Name: policy.Name}}})
+	require.NoError(t, err)
+
+	self, err := client.ReadSelfToken(ctx, created.SecretID)
+	require.NoError(t, err)
+	assert.Equal(t, created.AccessorID, self.AccessorID)
+	assert.True(t, self.HasPolicy("ns_config-edit"))
+
+	_, err = client.ReadSelfToken(ctx, "unknown-secret")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	tokens, meta, err := client.ListTokens(ctx, TokenFilter{PolicyID: policy.ID}, &QueryOptions{WaitIndex: 1, WaitTime: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Len(t, tokens, 1)
+	assert.Empty(t, tokens[0].SecretID)
+	assert.Equal(t, uint64(2), meta.LastIndex)
+	assert.Contains(t, fake.queries[len(fake.queries)-1], "index=1")
+	assert.Contains(t, fake.queries[len(fake.queries)-1], "wait=5000ms")
+	assert.Contains(t, fake.queries[len(fake.queries)-1], "policy="+policy.ID)
+}
+
+func TestErrorClassification(t *testing.T) {
+	_, client := newFakeConsul(t)
+	ctx := context.Background()
+
+	_, _, err := client.ListRoles(ctx, nil)
+	assert.ErrorIs(t, err, ErrServer)
+	var consulErr *Error
+	require.ErrorAs(t, err, &consulErr)
+	assert.Equal(t, http.StatusInternalServerError, consulErr.StatusCode)
+
+	_, err = NewClient(resty.New(), client.address, "weak-token").CreatePolicy(ctx, &Policy{Name: "p"})
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	_, err = client.ReadRole(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestScopedClient(t *testing.T) {
+	fake, client := newFakeConsul(t)
+	scoped := NewClient(resty.New(), client.address, adminToken, WithNamespace("team-a"), WithPartition("cloud-core"))
+
+	_, _, err := scoped.ListTokens(context.Background(), TokenFilter{PolicyID: "policy-1"}, nil)
+
+	require.NoError(t, err)
+	query := fake.queries[len(fake.queries)-1]
+	assert.Contains(t, query, "ns=team-a")
+	assert.Contains(t, query, "partition=cloud-core")
+	assert.Contains(t, query, "policy=policy-1")
+}
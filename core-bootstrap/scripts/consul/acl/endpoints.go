@@ -0,0 +1,201 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+func (c *Client) ReadPolicy(ctx context.Context, id string) (*Policy, error) {
+	var policy Policy
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/policy/" + url.PathEscape(id), result: &policy}); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (c *Client) ReadPolicyByName(ctx context.Context, name string) (*Policy, error) {
+	var policy Policy
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/policy/name/" + url.PathEscape(name), result: &policy}); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (c *Client) CreatePolicy(ctx context.Context, policy *Policy) (*Policy, error) {
+	var created Policy
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/policy", body: policy, result: &created}); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdatePolicy(ctx context.Context, policy *Policy) (*Policy, error) {
+	if policy.ID == "" {
+		return nil, fmt.Errorf("ID of consul policy '%s' is required for update", policy.Name)
+	}
+	var updated Policy
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/policy/" + url.PathEscape(policy.ID), body: policy, result: &updated}); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeletePolicy(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/v1/acl/policy/" + url.PathEscape(id)})
+	return err
+}
+
+func (c *Client) ListPolicies(ctx context.Context, options *QueryOptions) ([]Policy, QueryMeta, error) {
+	var policies []Policy
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/policies", query: options.values(nil), result: &policies})
+	return policies, meta, err
+}
+
+func (c *Client) ReadToken(ctx context.Context, accessorID string) (*Token, error) {
+	var token Token
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/token/" + url.PathEscape(accessorID), result: &token}); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ReadSelfToken reads the token identified by its secret instead of the client token
+func (c *Client) ReadSelfToken(ctx context.Context, secretID string) (*Token, error) {
+	var token Token
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/token/self", token: secretID, result: &token}); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (c *Client) CreateToken(ctx context.Context, token *Token) (*Token, error) {
+	var created Token
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/token", body: token, result: &created}); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateToken(ctx context.Context, token *Token) (*Token, error) {
+	if token.AccessorID == "" {
+		return nil, fmt.Errorf("accessor ID of consul token is required for update")
+	}
+	var updated Token
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/token/" + url.PathEscape(token.AccessorID), body: token, result: &updated}); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteToken(ctx context.Context, accessorID string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/v1/acl/token/" + url.PathEscape(accessorID)})
+	return err
+}
+
+// ListTokens returns tokens without secrets
+func (c *Client) ListTokens(ctx context.Context, filter TokenFilter, options *QueryOptions) ([]Token, QueryMeta, error) {
+	query := url.Values{}
+	if filter.PolicyID != "" {
+		query.Set("policy", filter.PolicyID)
+	}
+	if filter.RoleID != "" {
+		query.Set("role", filter.RoleID)
+	}
+	if filter.AuthMethod != "" {
+		query.Set("authmethod", filter.AuthMethod)
+	}
+	var tokens []Token
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/tokens", query: options.values(query), result: &tokens})
+	return tokens, meta, err
+}
+
+func (c *Client) ReadRole(ctx context.Context, id string) (*Role, error) {
+	var role Role
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/role/" + url.PathEscape(id), result: &role}); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (c *Client) ReadRoleByName(ctx context.Context, name string) (*Role, error) {
+	var role Role
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/role/name/" + url.PathEscape(name), result: &role}); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (c *Client) CreateRole(ctx context.Context, role *Role) (*Role, error) {
+	var created Role
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/role", body: role, result: &created}); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateRole(ctx context.Context, role *Role) (*Role, error) {
+	if role.ID == "" {
+		return nil, fmt.Errorf("ID of consul role '%s' is required for update", role.Name)
+	}
+	var updated Role
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/role/" + url.PathEscape(role.ID), body: role, result: &updated}); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteRole(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/v1/acl/role/" + url.PathEscape(id)})
+	return err
+}
+
+func (c *Client) ListRoles(ctx context.Context, options *QueryOptions) ([]Role, QueryMeta, error) {
+	var roles []Role
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/roles", query: options.values(nil), result: &roles})
+	return roles, meta, err
+}
+
+func (c *Client) ReadBindingRule(ctx context.Context, id string) (*BindingRule, error) {
+	var rule BindingRule
+	if _, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/binding-rule/" + url.PathEscape(id), result: &rule}); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (c *Client) CreateBindingRule(ctx context.Context, rule *BindingRule) (*BindingRule, error) {
+	var created BindingRule
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/binding-rule", body: rule, result: &created}); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) UpdateBindingRule(ctx context.Context, rule *BindingRule) (*BindingRule, error) {
+	if rule.ID == "" {
+		return nil, fmt.Errorf("ID of consul binding rule is required for update")
+	}
+	var updated BindingRule
+	if _, err := c.do(ctx, request{method: http.MethodPut, path: "/v1/acl/binding-rule/" + url.PathEscape(rule.ID), body: rule, result: &updated}); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteBindingRule(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{method: http.MethodDelete, path: "/v1/acl/binding-rule/" + url.PathEscape(id)})
+	return err
+}
+
+// ListBindingRules returns binding rules of the auth method or all rules if the method is empty
+func (c *Client) ListBindingRules(ctx context.Context, authMethod string, options *QueryOptions) ([]BindingRule, QueryMeta, error) {
+	query := url.Values{}
+	if authMethod != "" {
+		query.Set("authmethod", authMethod)
+	}
+	var rules []BindingRule
+	meta, err := c.do(ctx, request{method: http.MethodGet, path: "/v1/acl/binding-rules", query: options.values(query), result: &rules})
+	return rules, meta, err
+}
@@ -0,0 +1,83 @@
+package acl
+
+import "slices"
+
+// Consumer holds existing ACL objects created for a consumer of Consul and tokens holding them
+type Consumer struct {
+	// Tokens are accessor IDs of tokens known to belong to the consumer, e.g. the one stored in its secret
+	Tokens []string
+	// Role is nil if it does not exist
+	Role        *Role
+	RoleHolders []Token
+	Policies    []Policy
+	// PolicyHolders are tokens holding the policy directly by policy ID, e.g. tokens of versions without roles
+	PolicyHolders map[string][]Token
+}
+
+// Removal splits ACL objects of a consumer into the ones to delete and the ones to keep
+type Removal struct {
+	// Tokens are accessor IDs of tokens to delete
+	Tokens []string
+	// Role is the role to delete, nil if it is kept or does not exist
+	Role     *Role
+	Policies []Policy
+	// ForeignTokens hold the role or the policies but do not belong to the consumer, they are never deleted
+	ForeignTokens []Token
+	// KeptRole is the role held by foreign tokens, nil if there is none
+	KeptRole     *Role
+	KeptPolicies []Policy
+}
+
+// PlanRemoval decides which objects of the consumer are deleted. Tokens of the consumer and holders described as
+// description are deleted, other holders are foreign. Objects held by a foreign token are kept, so the token keeps its
+// permissions: the role together with all its policies and policies held directly.
+func PlanRemoval(consumer Consumer, description string) Removal {
+	var removal Removal
+	seen := make(map[string]bool)
+	for _, accessorID := range consumer.Tokens {
+		if accessorID != "" && !seen[accessorID] {
+			seen[accessorID] = true
+			removal.Tokens = append(removal.Tokens, accessorID)
+		}
+	}
+	// addHolders tells if any of the holders is foreign
+	addHolders := func(holders []Token) bool {
+		foreign := false
+		for _, holder := range holders {
+			own := holder.Description == description || slices.Contains(removal.Tokens, holder.AccessorID)
+			foreign = foreign || !own
+			if seen[holder.AccessorID] {
+				continue
+			}
+			seen[holder.AccessorID] = true
+			if own {
+				removal.Tokens = append(removal.Tokens, holder.AccessorID)
+			} else {
+				removal.ForeignTokens = append(removal.ForeignTokens, holder)
+			}
+		}
+		return foreign
+	}
+
+	keptByRole := make(map[string]bool)
+	if consumer.Role != nil {
+		if addHolders(consumer.RoleHolders) {
+			removal.KeptRole = consumer.Role
+			for _, link := range consumer.Role.Policies {
+				keptByRole[link.ID], keptByRole[link.Name] = true, true
+			}
+			delete(keptByRole, "")
+		} else {
+			removal.Role = consumer.Role
+		}
+	}
+	for _, policy := range consumer.Policies {
+		foreign := addHolders(consumer.PolicyHolders[policy.ID])
+		if foreign || keptByRole[policy.ID] || keptByRole[policy.Name] {
+			removal.KeptPolicies = append(removal.KeptPolicies, policy)
+		} else {
+			removal.Policies = append(removal.Policies, policy)
+		}
+	}
+	return removal
+}
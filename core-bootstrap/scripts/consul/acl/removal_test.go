@@ -0,0 +1,59 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ownDescription = "bootstrap token"
+
+func consumerObjects() Consumer {
+	return Consumer{
+		Tokens:      []string{"stored", "rotated"},
+		Role:        &Role{ID: "role-id", Name: "ns_config-server", Policies: []Link{{ID: "edit-id", Name: "ns_config-edit"}}},
+		RoleHolders: []Token{{AccessorID: "stored", Description: ownDescription}},
+		Policies:    []Policy{{ID: "edit-id", Name: "ns_config-edit"}, {ID: "logging-id", Name: "ns_config-server_logging"}},
+		PolicyHolders: map[string][]Token{
+			"logging-id": {{AccessorID: "legacy", Description: ownDescription}},
+		},
+	}
+}
+
+func TestPlanRemoval(t *testing.T) {
+	consumer := consumerObjects()
+	removal := PlanRemoval(consumer, ownDescription)
+
+	assert.Equal(t, []string{"stored", "rotated", "legacy"}, removal.Tokens)
+	assert.Equal(t, consumer.Role, removal.Role)
+	assert.Equal(t, consumer.Policies, removal.Policies)
+	assert.Empty(t, removal.ForeignTokens)
+	assert.Nil(t, removal.KeptRole)
+	assert.Empty(t, removal.KeptPolicies)
+}
+
+func TestPlanRemoval_ForeignHolderOfRole(t *testing.T) {
+	consumer := consumerObjects()
+	foreign := Token{AccessorID: "foreign", Description: "token of another team"}
+	consumer.RoleHolders = append(consumer.RoleHolders, foreign)
+	removal := PlanRemoval(consumer, ownDescription)
+
+	assert.Equal(t, []string{"stored", "rotated", "legacy"}, removal.Tokens)
+	assert.Equal(t, []Token{foreign}, removal.ForeignTokens)
+	assert.Nil(t, removal.Role)
+	assert.Equal(t, consumer.Role, removal.KeptRole)
+	assert.Equal(t, []Policy{consumer.Policies[0]}, removal.KeptPolicies, "policy of the kept role is kept")
+	assert.Equal(t, []Policy{consumer.Policies[1]}, removal.Policies)
+}
+
+func TestPlanRemoval_ForeignHolderOfPolicy(t *testing.T) {
+	consumer := consumerObjects()
+	foreign := Token{AccessorID: "foreign", Description: "token of another team"}
+	consumer.PolicyHolders["logging-id"] = append(consumer.PolicyHolders["logging-id"], foreign)
+	removal := PlanRemoval(consumer, ownDescription)
+
+	assert.Equal(t, []Token{foreign}, removal.ForeignTokens)
+	assert.Equal(t, consumer.Role, removal.Role)
+	assert.Equal(t, []Policy{consumer.Policies[0]}, removal.Policies)
+	assert.Equal(t, []Policy{consumer.Policies[1]}, removal.KeptPolicies)
+}
@@ -0,0 +1,81 @@
+package acl
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+)
+
+const (
+	// TokenMaxAgeAnnotation set on token secret makes the token rotated once it is older than the duration, e.g. `720h'
+	TokenMaxAgeAnnotation = "core-bootstrap.qubership.org/token-max-age"
+	// PreviousTokenAnnotation keeps accessor ID of the rotated token until it is deleted
+	PreviousTokenAnnotation = "core-bootstrap.qubership.org/previous-token-accessor"
+	// PreviousTokenDeleteAfterAnnotation keeps the time after which the rotated token is deleted
+	PreviousTokenDeleteAfterAnnotation = "core-bootstrap.qubership.org/previous-token-delete-after"
+)
+
+// PreviousToken returns accessor ID of the token replaced by rotation, kept in the annotations of the token secret,
+// and the time it can be deleted after. Empty accessor ID means there is no such token.
+func PreviousToken(annotations map[string]string) (string, time.Time, error) {
+	if annotations[PreviousTokenAnnotation] == "" {
+		return "", time.Time{}, nil
+	}
+	var deleteAfter time.Time
+	if value := annotations[PreviousTokenDeleteAfterAnnotation]; value != "" {
+		var err error
+		if deleteAfter, err = time.Parse(time.RFC3339, value); err != nil {
+			return "", time.Time{}, fmt.Errorf("invalid annotation %s: %w", PreviousTokenDeleteAfterAnnotation, err)
+		}
+	}
+	return annotations[PreviousTokenAnnotation], deleteAfter, nil
+}
+
+// TokenRotationDue tells if the token created at the time and stored in the secret with the annotations must be
+// rotated: on explicit request not served for the secret yet or when it is older than the max age,
+// TokenMaxAgeAnnotation overrides the given max age. Rotation is postponed while the token replaced by the previous
+// rotation is in its grace period, so it is never deleted before the grace period ends. The returned reason is logged.
+func TokenRotationDue(now time.Time, annotations map[string]string, created time.Time, request string, maxAge time.Duration) (bool, string, error) {
+	reason := ""
+	if credentials.RotationRequested(request, annotations) {
+		reason = fmt.Sprintf("rotation is requested by `%s'", request)
+	} else {
+		if value := annotations[TokenMaxAgeAnnotation]; value != "" {
+			var err error
+			if maxAge, err = time.ParseDuration(value); err != nil {
+				return false, "", fmt.Errorf("invalid annotation %s: %w", TokenMaxAgeAnnotation, err)
+			}
+		}
+		if maxAge <= 0 || created.IsZero() {
+			return false, "", nil
+		}
+		age := now.Sub(created)
+		if age < maxAge {
+			return false, "", nil
+		}
+		reason = fmt.Sprintf("token is %s old, which exceeds max age %s", age.Round(time.Second), maxAge)
+	}
+
+	previous, deleteAfter, err := PreviousToken(annotations)
+	if err != nil {
+		return false, "", err
+	}
+	if previous != "" && now.Before(deleteAfter) {
+		return false, fmt.Sprintf("%s, but rotation is postponed until grace period of previous token %s ends at %s",
+			reason, previous, deleteAfter.Format(time.RFC3339)), nil
+	}
+	return true, reason, nil
+}
+
+// RotationAnnotations returns annotations of the token secret written by rotation of the previous token: the token is
+// kept until deleteAfter and the served explicit request is recorded
+func RotationAnnotations(previous string, deleteAfter time.Time, request string) map[string]string {
+	annotations := map[string]string{
+		PreviousTokenAnnotation:            previous,
+		PreviousTokenDeleteAfterAnnotation: deleteAfter.UTC().Format(time.RFC3339),
+	}
+	maps.Copy(annotations, credentials.ServedRotationRequest(request))
+	return annotations
+}
@@ -0,0 +1,72 @@
+package acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenRotationDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-48 * time.Hour)
+
+	due, _, err := TokenRotationDue(now, nil, created, "", 0)
+	require.NoError(t, err)
+	assert.False(t, due, "no request and no max age")
+
+	due, reason, err := TokenRotationDue(now, nil, created, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, due, "older than max age")
+	assert.Equal(t, "token is 48h0m0s old, which exceeds max age 24h0m0s", reason)
+
+	due, _, err = TokenRotationDue(now, map[string]string{TokenMaxAgeAnnotation: "72h"}, created, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, due, "annotation overrides max age")
+
+	_, _, err = TokenRotationDue(now, map[string]string{TokenMaxAgeAnnotation: "month"}, created, "", 0)
+	assert.ErrorContains(t, err, "invalid annotation "+TokenMaxAgeAnnotation)
+}
+
+func TestTokenRotationDue_TwiceWithinGracePeriod(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	grace := time.Hour
+
+	due, reason, err := TokenRotationDue(now, map[string]string{}, now.Add(-time.Minute), "true", 0)
+	require.NoError(t, err)
+	require.True(t, due, "first run serves the request")
+	assert.Equal(t, "rotation is requested by `true'", reason)
+	annotations := RotationAnnotations("first", now.Add(grace), "true")
+
+	second := now.Add(10 * time.Minute)
+	due, reason, err = TokenRotationDue(second, annotations, now, "true", 0)
+	require.NoError(t, err)
+	assert.False(t, due, "the same request is not served again")
+	assert.Empty(t, reason)
+
+	due, reason, err = TokenRotationDue(second, annotations, now, "2025-06-01", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, due, "previous token is in grace period")
+	assert.Equal(t, "rotation is requested by `2025-06-01', but rotation is postponed until grace period of "+
+		"previous token first ends at 2025-06-01T01:00:00Z", reason)
+
+	due, _, err = TokenRotationDue(now.Add(2*grace), annotations, now, "2025-06-01", 0)
+	require.NoError(t, err)
+	assert.True(t, due, "grace period is over")
+}
+
+func TestPreviousToken(t *testing.T) {
+	accessor, _, err := PreviousToken(nil)
+	require.NoError(t, err)
+	assert.Empty(t, accessor)
+
+	deleteAfter := time.Date(2025, 6, 1, 1, 0, 0, 0, time.UTC)
+	accessor, after, err := PreviousToken(RotationAnnotations("first", deleteAfter, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "first", accessor)
+	assert.Equal(t, deleteAfter, after)
+
+	_, _, err = PreviousToken(map[string]string{PreviousTokenAnnotation: "first", PreviousTokenDeleteAfterAnnotation: "soon"})
+	assert.ErrorContains(t, err, "invalid annotation "+PreviousTokenDeleteAfterAnnotation)
+}
@@ -0,0 +1,88 @@
+package acl
+
+import "time"
+
+// Link references a policy or a role attached to a token or a role, either ID or Name is enough in requests
+type Link struct {
+	ID   string `json:"ID,omitempty"`
+	Name string `json:"Name,omitempty"`
+}
+
+type Policy struct {
+	ID          string   `json:"ID,omitempty"`
+	Name        string   `json:"Name"`
+	Description string   `json:"Description"`
+	Rules       string   `json:"Rules"`
+	Datacenters []string `json:"Datacenters,omitempty"`
+	CreateIndex uint64   `json:"CreateIndex,omitempty"`
+	ModifyIndex uint64   `json:"ModifyIndex,omitempty"`
+}
+
+type Token struct {
+	AccessorID     string     `json:"AccessorID,omitempty"`
+	SecretID       string     `json:"SecretID,omitempty"`
+	Description    string     `json:"Description"`
+	Policies       []Link     `json:"Policies,omitempty"`
+	Roles          []Link     `json:"Roles,omitempty"`
+	Local          bool       `json:"Local,omitempty"`
+	ExpirationTime *time.Time `json:"ExpirationTime,omitempty"`
+	CreateTime     time.Time  `json:"CreateTime,omitzero"`
+	CreateIndex    uint64     `json:"CreateIndex,omitempty"`
+	ModifyIndex    uint64     `json:"ModifyIndex,omitempty"`
+}
+
+// HasPolicy reports whether the policy is attached to the token directly
+func (t *Token) HasPolicy(name string) bool {
+	for _, policy := range t.Policies {
+		if policy.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole reports whether the role is attached to the token
+func (t *Token) HasRole(name string) bool {
+	for _, role := range t.Roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+type Role struct {
+	ID          string `json:"ID,omitempty"`
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	Policies    []Link `json:"Policies,omitempty"`
+	CreateIndex uint64 `json:"CreateIndex,omitempty"`
+	ModifyIndex uint64 `json:"ModifyIndex,omitempty"`
+}
+
+type BindType string
+
+const (
+	BindTypeService BindType = "service"
+	BindTypeNode    BindType = "node"
+	BindTypeRole    BindType = "role"
+	BindTypePolicy  BindType = "policy"
+)
+
+type BindingRule struct {
+	ID          string   `json:"ID,omitempty"`
+	Description string   `json:"Description"`
+	AuthMethod  string   `json:"AuthMethod"`
+	Selector    string   `json:"Selector,omitempty"`
+	BindType    BindType `json:"BindType"`
+	BindName    string   `json:"BindName"`
+	CreateIndex uint64   `json:"CreateIndex,omitempty"`
+	ModifyIndex uint64   `json:"ModifyIndex,omitempty"`
+}
+
+// TokenFilter narrows ListTokens to tokens linked with the policy, the role or created by the auth method
+type TokenFilter struct {
+	PolicyID   string
+	RoleID     string
+	AuthMethod string
+}
@@ -0,0 +1,150 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul/acl"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+type removal struct {
+	change taskmanager.Change
+	remove func(context.Context) error
+}
+
+// RemoveConsulPoliciesAndToken deletes everything CheckAndCreateConsulPoliciesAndToken created for the consumer:
+// the token stored in the secret and the one replaced by rotation, other tokens created by core-bootstrap holding
+// the role or the policies, the role, the policies and the secret itself. Objects already missing are skipped.
+// Tokens of others holding the role or the policies are kept and reported, and so are the role and the policies they
+// hold, so the tokens keep their permissions.
+func (c *Configurer) RemoveConsulPoliciesAndToken(ctx context.Context, secretName, roleName string, policies []Policy) error {
+	removals, kept, err := c.consumerRemovals(ctx, secretName, roleName, policies)
+	if err != nil {
+		return err
+	}
+	for _, change := range kept {
+		logger.InfoC(ctx, "Keeping %s '%s' %s", change.Kind, change.Name, change.Detail)
+		taskmanager.RecordChange(ctx, change)
+	}
+	for _, r := range removals {
+		logger.InfoC(ctx, "Deleting %s '%s'", r.change.Kind, r.change.Name)
+		if err := r.remove(ctx); err != nil {
+			return utils.LogError(logger, ctx, "error deleting %s '%s': %w", r.change.Kind, r.change.Name, err)
+		}
+		taskmanager.RecordChange(ctx, r.change)
+	}
+	return nil
+}
+
+// PlanConsulRemoval reports what RemoveConsulPoliciesAndToken would delete and keep
+func (c *Configurer) PlanConsulRemoval(ctx context.Context, secretName, roleName string, policies []Policy) ([]taskmanager.Change, error) {
+	removals, kept, err := c.consumerRemovals(ctx, secretName, roleName, policies)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]taskmanager.Change, 0, len(kept)+len(removals))
+	changes = append(changes, kept...)
+	for _, r := range removals {
+		changes = append(changes, r.change)
+	}
+	return changes, nil
+}
+
+// consumerRemovals lists existing objects of the consumer, tokens go first so no token is left without its role or policies.
+// Objects are split by acl.PlanRemoval: tokens stored in the secret or described as TokenDescription are removed, other
+// holders of the role or the policies are returned as kept together with the role and the policies they hold.
+func (c *Configurer) consumerRemovals(ctx context.Context, secretName, roleName string, policies []Policy) ([]removal, []taskmanager.Change, error) {
+	consumer := acl.Consumer{PolicyHolders: make(map[string][]acl.Token)}
+	secret, err := c.sink.Read(ctx, secretName)
+	if err != nil {
+		return nil, nil, utils.LogError(logger, ctx, "error reading secret %s: %w", secretName, err)
+	}
+	if secret != nil {
+		token, err := c.findTokenBySecret(ctx, string(secret.Data["token"]))
+		if err != nil {
+			return nil, nil, err
+		}
+		if token != nil {
+			consumer.Tokens = append(consumer.Tokens, token.AccessorID)
+		}
+		previousAccessor, _, err := previousToken(secretName, secret)
+		if err != nil {
+			return nil, nil, err
+		}
+		consumer.Tokens = append(consumer.Tokens, previousAccessor)
+	}
+
+	if consumer.Role, err = c.loadRole(ctx, roleName); err != nil {
+		return nil, nil, err
+	}
+	if consumer.Role != nil {
+		if consumer.RoleHolders, _, err = c.client.ListTokens(ctx, acl.TokenFilter{RoleID: consumer.Role.ID}, nil); err != nil {
+			return nil, nil, utils.LogError(logger, ctx, "error listing tokens of role '%s': %w", roleName, err)
+		}
+	}
+	for _, policy := range policies {
+		existing, err := c.loadPolicy(ctx, policy.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		consumer.Policies = append(consumer.Policies, *existing)
+		// tokens created by versions attaching policies directly
+		if consumer.PolicyHolders[existing.ID], _, err = c.client.ListTokens(ctx, acl.TokenFilter{PolicyID: existing.ID}, nil); err != nil {
+			return nil, nil, utils.LogError(logger, ctx, "error listing tokens of policy '%s': %w", policy.Name, err)
+		}
+	}
+
+	plan := acl.PlanRemoval(consumer, TokenDescription)
+	var kept []taskmanager.Change
+	for _, token := range plan.ForeignTokens {
+		kept = append(kept, taskmanager.Change{Action: taskmanager.ActionNone, Kind: KindToken, Name: token.AccessorID, Detail: "not created by core-bootstrap"})
+	}
+	if plan.KeptRole != nil {
+		kept = append(kept, taskmanager.Change{Action: taskmanager.ActionNone, Kind: KindRole, Name: plan.KeptRole.Name, Detail: "held by tokens not created by core-bootstrap"})
+	}
+	for _, policy := range plan.KeptPolicies {
+		kept = append(kept, taskmanager.Change{Action: taskmanager.ActionNone, Kind: KindPolicy, Name: policy.Name, Detail: "held by tokens not created by core-bootstrap"})
+	}
+
+	var removals []removal
+	for _, accessorID := range plan.Tokens {
+		removals = append(removals, removal{
+			change: taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindToken, Name: accessorID},
+			remove: ignoreNotFound(func(ctx context.Context) error { return c.client.DeleteToken(ctx, accessorID) }),
+		})
+	}
+	if role := plan.Role; role != nil {
+		removals = append(removals, removal{
+			change: taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindRole, Name: role.Name},
+			remove: ignoreNotFound(func(ctx context.Context) error { return c.client.DeleteRole(ctx, role.ID) }),
+		})
+	}
+	for _, policy := range plan.Policies {
+		removals = append(removals, removal{
+			change: taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindPolicy, Name: policy.Name},
+			remove: ignoreNotFound(func(ctx context.Context) error { return c.client.DeletePolicy(ctx, policy.ID) }),
+		})
+	}
+	if secret != nil {
+		removals = append(removals, removal{
+			change: taskmanager.Change{Action: taskmanager.ActionDelete, Kind: c.sink.Kind(), Name: secretName},
+			remove: func(ctx context.Context) error { return c.sink.Delete(ctx, secretName) },
+		})
+	}
+	return removals, kept, nil
+}
+
+func ignoreNotFound(remove func(context.Context) error) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if err := remove(ctx); err != nil && !errors.Is(err, acl.ErrNotFound) {
+			return fmt.Errorf("consul request failed: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package consul
+
+import (
+	"context"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+)
+
+const (
+	KindPolicy = "ConsulPolicy"
+	KindRole   = "ConsulRole"
+	KindToken  = "ConsulToken"
+)
+
+// Plan of consul task itself is empty, policies and tokens are planned by their consumers
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	return nil, nil
+}
+
+// PlanConsulPoliciesAndToken reports what CheckAndCreateConsulPoliciesAndToken would change, using read-only calls only
+func (c *Configurer) PlanConsulPoliciesAndToken(ctx context.Context, secretName, roleName string, requiredPolicies []Policy) ([]taskmanager.Change, error) {
+	var changes []taskmanager.Change
+	for _, policy := range requiredPolicies {
+		existing, err := c.loadPolicy(ctx, policy.Name)
+		if err != nil {
+			return nil, err
+		}
+		action := taskmanager.ActionNone
+		if existing == nil {
+			action = taskmanager.ActionCreate
+		} else if existing.Rules != policy.Rules || existing.Description != policy.Description {
+			action = taskmanager.ActionUpdate
+		}
+		changes = append(changes, taskmanager.Change{Action: action, Kind: KindPolicy, Name: policy.Name, Detail: policy.Rules})
+	}
+
+	role, err := c.loadRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	roleAction := taskmanager.ActionNone
+	if role == nil {
+		roleAction = taskmanager.ActionCreate
+	} else if !sameLinks(role.Policies, policyLinks(requiredPolicies)) {
+		roleAction = taskmanager.ActionUpdate
+	}
+	changes = append(changes, taskmanager.Change{Action: roleAction, Kind: KindRole, Name: roleName})
+
+	secret, err := c.sink.Read(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+	previousAccessor, deleteAfter, err := previousToken(secretName, secret)
+	if err != nil {
+		return nil, err
+	}
+	if previousAccessor != "" && !time.Now().Before(deleteAfter) {
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindToken, Name: previousAccessor, Detail: "rotated token"})
+	}
+
+	tokenFromSecret, err := c.GetConsulTokenFromSecret(ctx, secretName)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.findTokenBySecret(ctx, tokenFromSecret)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case token == nil:
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindToken, Name: secretName})
+	case !token.HasRole(roleName):
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionUpdate, Kind: KindToken, Name: token.AccessorID, Detail: "attach role " + roleName})
+	default:
+		rotate, err := c.rotationDue(ctx, secretName, secret, token)
+		if err != nil {
+			return nil, err
+		}
+		if !rotate {
+			changes = append(changes, credentials.Drift(c.sink, secretName, secret)...)
+			return append(changes, taskmanager.Change{Action: taskmanager.ActionNone, Kind: KindToken, Name: token.AccessorID}), nil
+		}
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindToken, Name: secretName, Detail: "rotation of " + token.AccessorID})
+	}
+
+	return append(changes, taskmanager.Change{Action: taskmanager.CreateOrUpdate(secret != nil), Kind: c.sink.Kind(), Name: secretName, Detail: credentials.DriftDetail(secret)}), nil
+}
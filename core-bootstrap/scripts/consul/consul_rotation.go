@@ -0,0 +1,112 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul/acl"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// rotationDue reports whether the token must be replaced because of explicit request or its age, see acl.TokenRotationDue
+func (c *Configurer) rotationDue(ctx context.Context, secretName string, secret *credentials.Credentials, token *acl.Token) (bool, error) {
+	var annotations map[string]string
+	if secret != nil {
+		annotations = secret.Annotations
+	}
+	due, reason, err := acl.TokenRotationDue(time.Now(), annotations, token.CreateTime, c.rotationRequest, c.tokenMaxAge)
+	if err != nil {
+		return false, fmt.Errorf("error checking rotation of token in secret %s: %w", secretName, err)
+	}
+	if reason != "" {
+		logger.InfoC(ctx, "Token %s of secret %s: %s", token.AccessorID, secretName, reason)
+	}
+	return due, nil
+}
+
+// RotateConsulToken creates a new token with the role and stores it in the secret.
+// The previous token is kept for the grace period, so its consumers can reload the secret, and deleted by a later run.
+// It fails while the token replaced by an earlier rotation is in its grace period.
+func (c *Configurer) RotateConsulToken(ctx context.Context, undoLog *utils.UndoLog, roleName string, previous *acl.Token, secretName string) error {
+	secret, err := c.sink.Read(ctx, secretName)
+	if err != nil {
+		return utils.LogError(logger, ctx, "error reading secret %s before update: %w", secretName, err)
+	}
+	rotated, keptUntil, err := previousToken(secretName, secret)
+	if err != nil {
+		return err
+	}
+	if rotated != "" {
+		return utils.LogError(logger, ctx, "token %s replaced by earlier rotation of secret %s is kept until %s, rotation is not possible before it is deleted",
+			rotated, secretName, keptUntil.Format(time.RFC3339))
+	}
+
+	logger.InfoC(ctx, "Rotating token %s with role '%s'", previous.AccessorID, roleName)
+	created, err := c.client.CreateToken(ctx, &acl.Token{
+		Description: TokenDescription,
+		Roles:       []acl.Link{{Name: roleName}},
+	})
+	if err != nil {
+		return utils.LogError(logger, ctx, "error creating consul token: %w", err)
+	}
+	undoLog.Add(fmt.Sprintf("delete created consul token %s", created.AccessorID), func(ctx context.Context) error {
+		return c.client.DeleteToken(ctx, created.AccessorID)
+	})
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindToken, Name: created.AccessorID, Detail: "rotation of " + previous.AccessorID})
+
+	deleteAfter := time.Now().Add(c.rotationGracePeriod).UTC()
+	annotations := acl.RotationAnnotations(previous.AccessorID, deleteAfter, c.rotationRequest)
+	if err := c.saveTokenSecret(ctx, created.SecretID, secretName, annotations); err != nil {
+		return utils.LogError(logger, ctx, "error store consul token to secret %s: %w", secretName, err)
+	}
+	undoLog.Add(fmt.Sprintf("restore secret %s", secretName), credentials.Restore(c.sink, secretName, secret))
+
+	logger.InfoC(ctx, "Token %s replaced by %s, previous token will be deleted by the first run after %s", previous.AccessorID, created.AccessorID, deleteAfter.Format(time.RFC3339))
+	return nil
+}
+
+// deletePreviousToken deletes the token replaced by rotation once its grace period is over.
+// Deleted tokens are not restored by Rollback.
+func (c *Configurer) deletePreviousToken(ctx context.Context, secretName string) error {
+	secret, err := c.sink.Read(ctx, secretName)
+	if err != nil {
+		return utils.LogError(logger, ctx, "error reading secret %s: %w", secretName, err)
+	}
+	accessorID, deleteAfter, err := previousToken(secretName, secret)
+	if err != nil || accessorID == "" {
+		return err
+	}
+	if time.Now().Before(deleteAfter) {
+		logger.InfoC(ctx, "Previous token %s is kept until %s", accessorID, deleteAfter.Format(time.RFC3339))
+		return nil
+	}
+
+	logger.InfoC(ctx, "Deleting previous token %s replaced by rotation", accessorID)
+	if err := c.client.DeleteToken(ctx, accessorID); err != nil && !errors.Is(err, acl.ErrNotFound) {
+		return utils.LogError(logger, ctx, "error deleting previous token %s: %w", accessorID, err)
+	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindToken, Name: accessorID, Detail: "rotated token"})
+
+	delete(secret.Annotations, acl.PreviousTokenAnnotation)
+	delete(secret.Annotations, acl.PreviousTokenDeleteAfterAnnotation)
+	if err := credentials.SaveOver(ctx, c.sink, secret, secretName, credentials.OwnedData(secret), nil); err != nil {
+		return utils.LogError(logger, ctx, "error updating secret %s: %w", secretName, err)
+	}
+	return nil
+}
+
+// previousToken returns accessor ID of the token replaced by rotation and the time it can be deleted after
+func previousToken(secretName string, secret *credentials.Credentials) (string, time.Time, error) {
+	if secret == nil {
+		return "", time.Time{}, nil
+	}
+	accessorID, deleteAfter, err := acl.PreviousToken(secret.Annotations)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading previous token of secret %s: %w", secretName, err)
+	}
+	return accessorID, deleteAfter, nil
+}
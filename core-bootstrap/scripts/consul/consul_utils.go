@@ -2,16 +2,26 @@ package consul
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
-
-	"github.com/go-resty/resty/v2"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	k8scredentials "github.com/netcracker/core-bootstrap/v2/credentials/kubernetes"
+	"github.com/netcracker/core-bootstrap/v2/httpclient"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul/acl"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul/kv"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TaskName = "consul"
+	// TokenDescription is the description of tokens created by core-bootstrap, including tokens of previous versions
+	TokenDescription = "bootstrap token"
 )
 
 var logger = logging.GetLogger("consul")
@@ -22,40 +32,68 @@ type Policy struct {
 	Rules       string
 }
 
-type TokenSelfResponse struct {
-	Policies   []Policy `json:"Policies"`
-	AccessorID string   `json:"AccessorID"`
-}
-
 type Configurer struct {
 	Namespace  string
 	Enabled    bool
 	Address    string
 	adminToken string
+	client     *acl.Client
+	kvClient   *kv.Client
+	// consulNamespace and consulPartition scope all ACL objects and keys in Consul Enterprise, empty means default
+	consulNamespace string
+	consulPartition string
+	// rotationRequest, tokenMaxAge and rotationGracePeriod control rotation of tokens created for consumers
+	rotationRequest     string
+	tokenMaxAge         time.Duration
+	rotationGracePeriod time.Duration
+	// sink stores tokens created for consumers
+	sink credentials.Sink
 }
 
 func New() *Configurer {
 	return &Configurer{}
 }
 
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
 func (c *Configurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
-	c.Enabled = utils.GetEnvBoolean(accessor, "CONSUL_ENABLED")
-	c.Address = strings.TrimRight(accessor("CONSUL_PUBLIC_URL"), "/")
-	c.adminToken = accessor("CONSUL_ADMIN_TOKEN")
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.Enabled = reader.Boolean("CONSUL_ENABLED")
+	c.Address = strings.TrimRight(reader.Optional("CONSUL_PUBLIC_URL"), "/")
+	reader.Check("CONSUL_PUBLIC_URL", configsource.ValidURL(c.Address))
+	c.adminToken = reader.Optional("CONSUL_ADMIN_TOKEN")
+	c.rotationRequest = credentials.RotationRequest(reader, "CONSUL_TOKEN_ROTATE")
+	c.tokenMaxAge = reader.Duration("CONSUL_TOKEN_MAX_AGE")
+	c.rotationGracePeriod = reader.Duration("CONSUL_TOKEN_ROTATION_GRACE_PERIOD")
 
 	if c.Enabled && (c.Address == "" || c.adminToken == "") {
-		return fmt.Errorf("consul public URL and admin token are required if CONSUL_ENABLED true")
-	}
-	return nil
+		reader.Fail("consul public URL and admin token are required if CONSUL_ENABLED true")
+	}
+	c.consulNamespace = reader.Optional("CONSUL_NAMESPACE")
+	c.consulPartition = reader.Optional("CONSUL_PARTITION")
+	httpClient := utils.NewEndpointClient(reader, httpclient.Consul, c.Namespace)
+	c.client = acl.NewClient(httpClient, c.Address, c.adminToken,
+		acl.WithNamespace(c.consulNamespace), acl.WithPartition(c.consulPartition))
+	c.kvClient = kv.NewClient(httpClient, c.Address, c.adminToken,
+		kv.WithNamespace(c.consulNamespace), kv.WithPartition(c.consulPartition))
+	c.sink = k8scredentials.ReadSink(reader, c.Namespace)
+	return reader.Err()
+}
+
+// KVClient returns client of Consul KV authorized with admin token, available after Configure
+func (c *Configurer) KVClient() *kv.Client {
+	return c.kvClient
 }
 
 func (c *Configurer) Execute(ctx context.Context) error {
 	return nil
 }
 
-func GetConsulTokenFromSecret(ctx context.Context, namespace, secretName string) (string, error) {
-	secret, err := utils.GetExistingSecret(ctx, namespace, secretName)
+func (c *Configurer) GetConsulTokenFromSecret(ctx context.Context, secretName string) (string, error) {
+	secret, err := c.sink.Read(ctx, secretName)
 	if err != nil {
 		return "", utils.LogError(logger, ctx, "error getting token from secret: %v", err)
 	}
@@ -72,168 +110,156 @@ func GetConsulTokenFromSecret(ctx context.Context, namespace, secretName string)
 	return string(token), nil
 }
 
-func SaveConsulTokenSecret(ctx context.Context, namespace, token, secretName string) error {
+func (c *Configurer) SaveConsulTokenSecret(ctx context.Context, token, secretName string) error {
+	return c.saveTokenSecret(ctx, token, secretName, nil)
+}
+
+// saveTokenSecret keeps annotations of the existing secret, e.g. token max age, adding the given ones
+func (c *Configurer) saveTokenSecret(ctx context.Context, token, secretName string, annotations map[string]string) error {
 	logger.InfoC(ctx, "Saving secret '%s'...", secretName)
 	secretData := map[string][]byte{
 		"token": []byte(token),
 	}
-	secret := &v1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: secretName,
-			Labels: map[string]string{
-				"app.kubernetes.io/part-of":    "Cloud-Core",
-				"app.kubernetes.io/managed-by": "saasDeployer",
-			},
-		},
-		Data: secretData,
-		Type: v1.SecretTypeOpaque,
-	}
-	err := utils.CreateOrUpdateSecret(ctx, namespace, secret)
-	if err != nil {
+	if err := credentials.Save(ctx, c.sink, secretName, secretData, annotations); err != nil {
 		return utils.LogError(logger, ctx, "error creating or updating secret: %w", err)
 	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionApply, Kind: c.sink.Kind(), Name: secretName})
 
 	logger.InfoC(ctx, "Secret '%s' created successfully", secretName)
 	return nil
 }
 
-func SendConsulRequest(ctx context.Context, url, method, token string, payload interface{}, errCodes ...int) (map[string]interface{}, error) {
-	if len(errCodes) == 0 {
-		errCodes = []int{404}
-	}
-
-	resp, err := SendConsulRequestRaw(ctx, url, method, token, payload, errCodes...)
-	if err != nil {
-		return nil, utils.LogError(logger, ctx, "Error during SendConsulRequestRaw: %w", err)
-	}
-
-	if resp == nil {
-		return nil, nil
-	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp.Body(), &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return result, nil
-}
-
-func SendConsulRequestRaw(ctx context.Context, url, method, token string, payload interface{}, errCodes ...int) (*resty.Response, error) {
-	resp, err := utils.RestyClient.R().
-		SetContext(ctx).
-		SetHeader("X-Consul-Token", token).
-		SetHeader("Content-Type", "application/json").
-		SetBody(payload).
-		Execute(method, url)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-
-	for _, code := range errCodes {
-		if resp.StatusCode() == code {
-			logger.InfoC(ctx, "url '%s' returned status code %d", url, resp.StatusCode())
-			return nil, nil
-		}
-	}
-
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode(), resp.String())
-	}
-
-	return resp, nil
-}
-
-func (c *Configurer) CreateConsulToken(ctx context.Context, policies []Policy, existingToken, secretName string) error {
-	logger.InfoC(ctx, "Creating or updating policy and token")
+// CreateConsulToken creates a token holding the role or, if the accessor of existing token is given, replaces its policies and roles with the role
+func (c *Configurer) CreateConsulToken(ctx context.Context, undoLog *utils.UndoLog, roleName, existingToken, secretName string) error {
+	logger.InfoC(ctx, "Creating or updating token with role '%s'", roleName)
 
-	var payloadPolicies []map[string]string
-	for _, policy := range policies {
-		payloadPolicies = append(payloadPolicies, map[string]string{"Name": policy.Name})
-	}
-
-	tokenPayload := map[string]interface{}{
-		"Description": "bootstrap token",
-		"Policies":    payloadPolicies,
+	token := &acl.Token{
+		AccessorID:  existingToken,
+		Description: TokenDescription,
+		Roles:       []acl.Link{{Name: roleName}},
 	}
 
-	var tokenURL string
+	var saved *acl.Token
 	if existingToken == "" {
-		tokenURL = fmt.Sprintf("%s/v1/acl/token", c.Address)
+		created, err := c.client.CreateToken(ctx, token)
+		if err != nil {
+			return utils.LogError(logger, ctx, "error creating consul token: %w", err)
+		}
+		undoLog.Add(fmt.Sprintf("delete created consul token %s", created.AccessorID), func(ctx context.Context) error {
+			return c.client.DeleteToken(ctx, created.AccessorID)
+		})
+		saved = created
 	} else {
-		tokenURL = fmt.Sprintf("%s/v1/acl/token/%s", c.Address, existingToken)
-	}
-
-	response, err := SendConsulRequest(ctx, tokenURL, http.MethodPut, c.adminToken, tokenPayload)
+		previous, err := c.client.ReadToken(ctx, existingToken)
+		if err != nil {
+			return utils.LogError(logger, ctx, "Error reading token before update: %w", err)
+		}
+		if saved, err = c.client.UpdateToken(ctx, token); err != nil {
+			return utils.LogError(logger, ctx, "error updating consul token %s: %w", existingToken, err)
+		}
+		undoLog.Add(fmt.Sprintf("restore policies of consul token %s", existingToken), func(ctx context.Context) error {
+			_, err := c.client.UpdateToken(ctx, &acl.Token{
+				AccessorID:  previous.AccessorID,
+				Description: previous.Description,
+				Policies:    previous.Policies,
+				Roles:       previous.Roles,
+			})
+			return err
+		})
+	}
+	if saved.SecretID == "" {
+		return utils.LogError(logger, ctx, "consul did not return SecretID of token %s", saved.AccessorID)
+	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.CreateOrUpdate(existingToken != ""), Kind: KindToken, Name: saved.AccessorID})
+
+	restoreSecret, err := credentials.Restorer(ctx, c.sink, secretName)
 	if err != nil {
-		return utils.LogError(logger, ctx, "Error during sendConsulRequest: %w", err)
+		return utils.LogError(logger, ctx, "error reading secret %s before update: %w", secretName, err)
 	}
-
-	secretId, ok := response["SecretID"].(string)
-	if !ok {
-		return utils.LogError(logger, ctx, "error getting SecretID from resp body: %v", response)
+	// a new token serves the explicit rotation request, so it is not rotated by the next run
+	var annotations map[string]string
+	if existingToken == "" {
+		annotations = credentials.ServedRotationRequest(c.rotationRequest)
 	}
-
-	if err := SaveConsulTokenSecret(ctx, c.Namespace, secretId, secretName); err != nil {
+	if err := c.saveTokenSecret(ctx, saved.SecretID, secretName, annotations); err != nil {
 		return utils.LogError(logger, ctx, "error store consul token to secret %s: %w", secretName, err)
 	}
+	undoLog.Add(fmt.Sprintf("restore secret %s", secretName), restoreSecret)
 
 	return nil
 }
 
 func (c *Configurer) LoadPolicyID(ctx context.Context, policyName string) (string, error) {
 	logger.InfoC(ctx, "Loading policy ID for policy '%s'", policyName)
-	url := fmt.Sprintf("%s/v1/acl/policy/name/%s", c.Address, policyName)
-
-	result, err := SendConsulRequest(ctx, url, http.MethodGet, c.adminToken, nil, 404, 403)
+	policy, err := c.loadPolicy(ctx, policyName)
 	if err != nil {
-		return "", utils.LogError(logger, ctx, "Error getting policy id from url %s: %w", url, err)
+		return "", err
 	}
 
-	if result == nil {
+	if policy == nil {
 		logger.InfoC(ctx, "No policy id found for policy '%s'", policyName)
 		return "", nil
 	}
 
-	policyID, exists := result["ID"].(string)
-	if !exists {
-		return "", utils.LogError(logger, ctx, "policy ID not found in response")
-	}
+	return policy.ID, nil
+}
 
-	return policyID, nil
+// loadPolicy returns nil if the policy does not exist
+func (c *Configurer) loadPolicy(ctx context.Context, policyName string) (*acl.Policy, error) {
+	policy, err := c.client.ReadPolicyByName(ctx, policyName)
+	if errors.Is(err, acl.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.LogError(logger, ctx, "Error getting policy '%s': %w", policyName, err)
+	}
+	return policy, nil
 }
 
-func (c *Configurer) CreateOrUpdatePolicy(ctx context.Context, policyID string, policy Policy) error {
+func (c *Configurer) CreateOrUpdatePolicy(ctx context.Context, undoLog *utils.UndoLog, policyID string, policy Policy) error {
 	logger.InfoC(ctx, "Creating or updating Consul policy '%s'", policy.Name)
-	policyPayload := map[string]interface{}{
-		"Name":        policy.Name,
-		"Description": policy.Description,
-		"Rules":       policy.Rules,
+	desired := &acl.Policy{
+		ID:          policyID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Rules:       policy.Rules,
 	}
+	logger.InfoC(ctx, "Policy payload: %+v", *desired)
 
-	var policyUrl string
 	if policyID == "" {
 		logger.InfoC(ctx, "Policy '%s' does not exist, creating new policy", policy.Name)
-		policyUrl = fmt.Sprintf("%s/v1/acl/policy", c.Address)
+		created, err := c.client.CreatePolicy(ctx, desired)
+		if err != nil {
+			return utils.LogError(logger, ctx, "error creating policy: %w", err)
+		}
+		undoLog.Add(fmt.Sprintf("delete created consul policy '%s'", policy.Name), func(ctx context.Context) error {
+			return c.client.DeletePolicy(ctx, created.ID)
+		})
 	} else {
 		logger.InfoC(ctx, "Policy '%s' already exists, updating", policy.Name)
-		policyUrl = fmt.Sprintf("%s/v1/acl/policy/%s", c.Address, policyID)
-	}
-
-	logger.InfoC(ctx, "Policy URL: '%s', policy payload: %+v", policyUrl, policyPayload)
-
-	if _, err := SendConsulRequest(ctx, policyUrl, http.MethodPut, c.adminToken, policyPayload); err != nil {
-		return utils.LogError(logger, ctx, "error creating or updating policy: %w", err)
+		previous, err := c.client.ReadPolicy(ctx, policyID)
+		if err != nil {
+			return utils.LogError(logger, ctx, "error reading policy before update: %w", err)
+		}
+		if _, err := c.client.UpdatePolicy(ctx, desired); err != nil {
+			return utils.LogError(logger, ctx, "error updating policy: %w", err)
+		}
+		undoLog.Add(fmt.Sprintf("restore consul policy '%s'", policy.Name), func(ctx context.Context) error {
+			_, err := c.client.UpdatePolicy(ctx, previous)
+			return err
+		})
 	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.CreateOrUpdate(policyID != ""), Kind: KindPolicy, Name: policy.Name})
 
 	return nil
 }
 
-// last argument is for backward compatibility, check CleanupDuplicateTokens function
-func (c *Configurer) CheckAndCreateConsulPoliciesAndToken(ctx context.Context, secretName string, requiredPolicies []Policy, dublicatedPolicy string) error {
+// CheckAndCreateConsulPoliciesAndToken makes the token stored in the secret hold the role with the required policies.
+// Tokens created by previous versions with policies attached directly are migrated to the role keeping their secret.
+// Actions reverting the changes are added to the undo log of the calling task.
+func (c *Configurer) CheckAndCreateConsulPoliciesAndToken(ctx context.Context, undoLog *utils.UndoLog, secretName, roleName string, requiredPolicies []Policy) error {
 	// First check if we have an existing token
-	tokenFromSecret, err := GetConsulTokenFromSecret(ctx, c.Namespace, secretName)
+	tokenFromSecret, err := c.GetConsulTokenFromSecret(ctx, secretName)
 	if err != nil {
 		return utils.LogError(logger, ctx, "Error getting token from secret: %w", err)
 	}
@@ -246,34 +272,47 @@ func (c *Configurer) CheckAndCreateConsulPoliciesAndToken(ctx context.Context, s
 		}
 
 		// Create or update the policy
-		err = c.CreateOrUpdatePolicy(ctx, policyID, policy)
+		err = c.CreateOrUpdatePolicy(ctx, undoLog, policyID, policy)
 		if err != nil {
 			return utils.LogError(logger, ctx, "Error creating/updating policy: %w", err)
 		}
 	}
 
-	// If we have an existing token, check if it needs to be updated
-	if tokenFromSecret != "" {
-		// Check if the token has all required policies
-		tokenFromSecret, err = c.CheckRequiredPoliciesOnToken(ctx, tokenFromSecret, requiredPolicies)
-		if err != nil {
-			return utils.LogError(logger, ctx, "Error checking required policies: %w", err)
-		}
+	if err := c.CreateOrUpdateRole(ctx, undoLog, roleName, requiredPolicies); err != nil {
+		return utils.LogError(logger, ctx, "Error creating/updating role: %w", err)
+	}
 
-		// If token is valid and has all required policies, we're done
-		if tokenFromSecret != "" {
-			logger.InfoC(ctx, "Token already has required policies, skipping update")
-			return nil
-		}
+	if err := c.deletePreviousToken(ctx, secretName); err != nil {
+		return utils.LogError(logger, ctx, "Error deleting previous token: %w", err)
 	}
 
-	// Clean up any duplicate tokens
-	existingToken, err := c.CleanupDuplicateTokens(ctx, dublicatedPolicy)
+	tokenInfo, err := c.findTokenBySecret(ctx, tokenFromSecret)
 	if err != nil {
-		return utils.LogError(logger, ctx, "Error during CleanupDuplicateTokens: %w", err)
+		return utils.LogError(logger, ctx, "Error reading token from secret: %w", err)
+	}
+	existingToken := ""
+	if tokenInfo != nil {
+		if tokenInfo.HasRole(roleName) {
+			secret, err := c.sink.Read(ctx, secretName)
+			if err != nil {
+				return utils.LogError(logger, ctx, "Error reading secret %s: %w", secretName, err)
+			}
+			rotate, err := c.rotationDue(ctx, secretName, secret, tokenInfo)
+			if err != nil {
+				return utils.LogError(logger, ctx, "Error checking token rotation: %w", err)
+			}
+			if rotate {
+				return c.RotateConsulToken(ctx, undoLog, roleName, tokenInfo, secretName)
+			}
+			logger.InfoC(ctx, "Token already has role '%s', skipping update", roleName)
+			credentials.ReportDrift(ctx, c.sink, secretName, secret)
+			return nil
+		}
+		logger.InfoC(ctx, "Token %s has no role '%s', attaching it instead of directly attached policies", tokenInfo.AccessorID, roleName)
+		existingToken = tokenInfo.AccessorID
 	}
 
-	err = c.CreateConsulToken(ctx, requiredPolicies, existingToken, secretName)
+	err = c.CreateConsulToken(ctx, undoLog, roleName, existingToken, secretName)
 	if err != nil {
 		return utils.LogError(logger, ctx, "Error createConsulToken: %w", err)
 	}
@@ -281,119 +320,99 @@ func (c *Configurer) CheckAndCreateConsulPoliciesAndToken(ctx context.Context, s
 	return nil
 }
 
-func (c *Configurer) CheckRequiredPoliciesOnToken(ctx context.Context, tokenFromSecret string, requiredPolicies []Policy) (string, error) {
-	if tokenFromSecret == "" {
-		// No token provided; nothing to check.
-		return "", nil
-	}
-
-	logger.InfoC(ctx, "Checking required policies on token")
-
-	tokenInfo, err := c.GetTokenInfo(ctx, tokenFromSecret)
+// CreateOrUpdateRole makes the role hold exactly the policies
+func (c *Configurer) CreateOrUpdateRole(ctx context.Context, undoLog *utils.UndoLog, roleName string, policies []Policy) error {
+	previous, err := c.loadRole(ctx, roleName)
 	if err != nil {
-		return "", err
+		return err
 	}
-
-	for _, required := range requiredPolicies {
-		found := false
-		for _, policy := range tokenInfo.Policies {
-			if policy.Name == required.Name {
-				found = true
-				break
-			}
-		}
-		if !found {
-			logger.InfoC(ctx, "Required policy '%s' not found on token. New token will be created.", required.Name)
-			return "", nil
-		}
+	desired := &acl.Role{
+		Name:        roleName,
+		Description: "bootstrap role",
+		Policies:    policyLinks(policies),
 	}
 
-	return tokenFromSecret, nil
+	if previous == nil {
+		logger.InfoC(ctx, "Role '%s' does not exist, creating new role", roleName)
+		created, err := c.client.CreateRole(ctx, desired)
+		if err != nil {
+			return utils.LogError(logger, ctx, "error creating role '%s': %w", roleName, err)
+		}
+		undoLog.Add(fmt.Sprintf("delete created consul role '%s'", roleName), func(ctx context.Context) error {
+			return c.client.DeleteRole(ctx, created.ID)
+		})
+		taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindRole, Name: roleName})
+		return nil
+	}
+
+	if sameLinks(previous.Policies, desired.Policies) {
+		logger.InfoC(ctx, "Role '%s' already has required policies", roleName)
+		return nil
+	}
+	logger.InfoC(ctx, "Role '%s' already exists, updating its policies", roleName)
+	desired.ID = previous.ID
+	if _, err := c.client.UpdateRole(ctx, desired); err != nil {
+		return utils.LogError(logger, ctx, "error updating role '%s': %w", roleName, err)
+	}
+	undoLog.Add(fmt.Sprintf("restore consul role '%s'", roleName), func(ctx context.Context) error {
+		_, err := c.client.UpdateRole(ctx, previous)
+		return err
+	})
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionUpdate, Kind: KindRole, Name: roleName})
+	return nil
 }
 
-func (c *Configurer) GetTokenInfo(ctx context.Context, token string) (*TokenSelfResponse, error) {
-	url := strings.TrimRight(c.Address, "/") + "/v1/acl/token/self"
-
-	respMap, err := SendConsulRequest(ctx, url, "GET", token, nil)
-	if err != nil {
-		return nil, err
-	}
-	if respMap == nil {
-		return nil, fmt.Errorf("no response received from Consul")
+// loadRole returns nil if the role does not exist
+func (c *Configurer) loadRole(ctx context.Context, roleName string) (*acl.Role, error) {
+	role, err := c.client.ReadRoleByName(ctx, roleName)
+	if errors.Is(err, acl.ErrNotFound) {
+		return nil, nil
 	}
-
-	b, err := json.Marshal(respMap)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+		return nil, utils.LogError(logger, ctx, "Error getting role '%s': %w", roleName, err)
 	}
+	return role, nil
+}
 
-	var tokenInfo TokenSelfResponse
-	if err := json.Unmarshal(b, &tokenInfo); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal token info: %w", err)
+// findTokenBySecret returns nil if the secret is empty or the token was deleted from Consul
+func (c *Configurer) findTokenBySecret(ctx context.Context, secretID string) (*acl.Token, error) {
+	if secretID == "" {
+		return nil, nil
 	}
-
-	return &tokenInfo, nil
+	token, err := c.GetTokenInfo(ctx, secretID)
+	if errors.Is(err, acl.ErrNotFound) {
+		logger.InfoC(ctx, "Token from secret does not exist in Consul. New token will be created.")
+		return nil, nil
+	}
+	return token, err
 }
 
-// big ugly function for backward compatibility, because config-server coluld have policy with config, but not with log and we need to add it
-// futhermore, there was a bug, when several tokens with the same name could exist, that's why we clear them
-func (c *Configurer) CleanupDuplicateTokens(ctx context.Context, policyName string) (string, error) {
-	tokensURL := strings.TrimRight(c.Address, "/") + "/v1/acl/tokens"
+// GetTokenInfo reads the token by its secret
+func (c *Configurer) GetTokenInfo(ctx context.Context, token string) (*acl.Token, error) {
+	return c.client.ReadSelfToken(ctx, token)
+}
 
-	resp, err := SendConsulRequestRaw(ctx, tokensURL, http.MethodGet, c.adminToken, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to list tokens: %w", err)
+func policyLinks(policies []Policy) []acl.Link {
+	links := make([]acl.Link, 0, len(policies))
+	for _, policy := range policies {
+		links = append(links, acl.Link{Name: policy.Name})
 	}
+	return links
+}
 
-	var tokens []map[string]interface{}
-	{
-		if err := json.Unmarshal(resp.Body(), &tokens); err != nil {
-			return "", fmt.Errorf("failed to unmarshal tokens list: %w", err)
-		}
+// sameLinks compares links by names regardless of their order
+func sameLinks(actual, expected []acl.Link) bool {
+	if len(actual) != len(expected) {
+		return false
 	}
-
-	var matchingTokens []string
-	for _, token := range tokens {
-		accessorID, ok := token["AccessorID"].(string)
-		if !ok {
-			continue
-		}
-		policies, ok := token["Policies"].([]interface{})
-		if !ok {
-			continue
-		}
-		for _, p := range policies {
-			pMap, ok := p.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			if name, ok := pMap["Name"].(string); ok && name == policyName {
-				matchingTokens = append(matchingTokens, accessorID)
-				break
-			}
-		}
+	names := make(map[string]bool, len(actual))
+	for _, link := range actual {
+		names[link.Name] = true
 	}
-
-	tokenCount := len(matchingTokens)
-	if tokenCount >= 1 {
-		firstToken := matchingTokens[0]
-		logger.InfoC(ctx, "Found %d tokens with policy %s. Will keep only the first one (%s) and delete all others.", tokenCount, policyName, firstToken)
-
-		// Delete all tokens except the first one.
-		if tokenCount > 1 {
-			for _, tokenToDelete := range matchingTokens[1:] {
-				deleteURL := fmt.Sprintf("%s/v1/acl/token/%s", strings.TrimRight(c.Address, "/"), tokenToDelete)
-				logger.InfoC(ctx, "Deleting token with accessor ID: %s", tokenToDelete)
-				if _, err := SendConsulRequest(ctx, deleteURL, http.MethodDelete, c.adminToken, nil); err != nil {
-					logger.InfoC(ctx, "Error deleting token %s: %v", tokenToDelete, err)
-				}
-			}
+	for _, link := range expected {
+		if !names[link.Name] {
+			return false
 		}
-
-		logger.InfoC(ctx, "Will update the remaining token with accessor ID: %s", firstToken)
-		return firstToken, nil
 	}
-
-	logger.InfoC(ctx, "No existing tokens found with policy %s. Will create a new one.", policyName)
-	return "", nil
+	return true
 }
@@ -0,0 +1,127 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Pair is a Consul KV entry, Value is decoded from base64
+type Pair struct {
+	Key         string `json:"Key"`
+	Value       []byte `json:"Value"`
+	Flags       uint64 `json:"Flags"`
+	CreateIndex uint64 `json:"CreateIndex"`
+	ModifyIndex uint64 `json:"ModifyIndex"`
+}
+
+// Client calls Consul KV HTTP API with the token it was created with
+type Client struct {
+	http      *resty.Client
+	address   string
+	token     string
+	namespace string
+	partition string
+}
+
+type Option func(*Client)
+
+// WithNamespace scopes all requests to the Consul Enterprise namespace
+func WithNamespace(namespace string) Option {
+	return func(c *Client) {
+		c.namespace = namespace
+	}
+}
+
+// WithPartition scopes all requests to the Consul Enterprise admin partition
+func WithPartition(partition string) Option {
+	return func(c *Client) {
+		c.partition = partition
+	}
+}
+
+func NewClient(httpClient *resty.Client, address, token string, options ...Option) *Client {
+	c := &Client{http: httpClient, address: strings.TrimRight(address, "/"), token: token}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Get returns nil if the key does not exist
+func (c *Client) Get(ctx context.Context, key string) (*Pair, error) {
+	resp, err := c.request(ctx).Get(c.keyURL(key))
+	if err != nil {
+		return nil, fmt.Errorf("consul GET key '%s' failed: %w", key, err)
+	}
+	if resp.StatusCode() == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("consul GET key '%s' failed with status %d: %s", key, resp.StatusCode(), resp.String())
+	}
+
+	var pairs []Pair
+	if err := json.Unmarshal(resp.Body(), &pairs); err != nil {
+		return nil, fmt.Errorf("failed to decode consul key '%s': %w", key, err)
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	return &pairs[0], nil
+}
+
+// CAS writes the value only if ModifyIndex of the key still equals modifyIndex, zero index means the key must not exist.
+// It returns false if the key was modified concurrently.
+func (c *Client) CAS(ctx context.Context, key string, value []byte, modifyIndex uint64) (bool, error) {
+	resp, err := c.request(ctx).
+		SetQueryParam("cas", strconv.FormatUint(modifyIndex, 10)).
+		SetBody(value).
+		Put(c.keyURL(key))
+	return c.result(resp, err, http.MethodPut, key)
+}
+
+// DeleteCAS deletes the key only if ModifyIndex of the key still equals modifyIndex
+func (c *Client) DeleteCAS(ctx context.Context, key string, modifyIndex uint64) (bool, error) {
+	resp, err := c.request(ctx).
+		SetQueryParam("cas", strconv.FormatUint(modifyIndex, 10)).
+		Delete(c.keyURL(key))
+	return c.result(resp, err, http.MethodDelete, key)
+}
+
+func (c *Client) request(ctx context.Context) *resty.Request {
+	req := c.http.R().
+		SetContext(ctx).
+		SetHeader("X-Consul-Token", c.token)
+	if c.namespace != "" {
+		req.SetQueryParam("ns", c.namespace)
+	}
+	if c.partition != "" {
+		req.SetQueryParam("partition", c.partition)
+	}
+	return req
+}
+
+func (c *Client) keyURL(key string) string {
+	segments := strings.Split(strings.Trim(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return c.address + "/v1/kv/" + strings.Join(segments, "/")
+}
+
+func (c *Client) result(resp *resty.Response, err error, method, key string) (bool, error) {
+	if err != nil {
+		return false, fmt.Errorf("consul %s key '%s' failed: %w", method, key, err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return false, fmt.Errorf("consul %s key '%s' failed with status %d: %s", method, key, resp.StatusCode(), resp.String())
+	}
+	return strings.TrimSpace(resp.String()) == "true", nil
+}
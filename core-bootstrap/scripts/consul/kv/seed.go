@@ -0,0 +1,172 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	KindKey = "ConsulKey"
+	// casAttempts limits re-reads of a key modified concurrently with seeding
+	casAttempts = 3
+)
+
+// Entry is a key to seed, keys with Overwrite false are only created if absent
+type Entry struct {
+	Key       string
+	Value     string
+	Overwrite bool
+}
+
+// seedDocument is the format of seed files: key paths to values, `${NAMESPACE}' in key paths is replaced
+//
+//	create:
+//	  config/${NAMESPACE}/application/feature.enabled: "false"
+//	overwrite:
+//	  logging/${NAMESPACE}/config-server/root: INFO
+type seedDocument struct {
+	Create    map[string]string `json:"create"`
+	Overwrite map[string]string `json:"overwrite"`
+}
+
+// ParseSeed reads entries of the named YAML or JSON seed document, entries are sorted by keys
+func ParseSeed(name string, data []byte, namespace string) ([]Entry, error) {
+	var document seedDocument
+	if err := yaml.UnmarshalStrict(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid consul KV seed '%s': %w", name, err)
+	}
+
+	var entries []Entry
+	seen := make(map[string]bool)
+	add := func(values map[string]string, overwrite bool) error {
+		for key, value := range values {
+			key = strings.Trim(strings.ReplaceAll(key, "${NAMESPACE}", namespace), "/")
+			if key == "" {
+				return fmt.Errorf("invalid consul KV seed '%s': empty key", name)
+			}
+			if seen[key] {
+				return fmt.Errorf("invalid consul KV seed '%s': key '%s' is declared twice", name, key)
+			}
+			seen[key] = true
+			entries = append(entries, Entry{Key: key, Value: value, Overwrite: overwrite})
+		}
+		return nil
+	}
+	if err := add(document.Create, false); err != nil {
+		return nil, err
+	}
+	if err := add(document.Overwrite, true); err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
+// Applied describes a written key and how to revert it
+type Applied struct {
+	Change taskmanager.Change
+	Undo   func(context.Context) error
+}
+
+// Seeder writes entries to Consul KV
+type Seeder struct {
+	client *Client
+}
+
+func NewSeeder(client *Client) *Seeder {
+	return &Seeder{client: client}
+}
+
+// Plan returns changes Apply would make, reading keys only
+func (s *Seeder) Plan(ctx context.Context, entries []Entry) ([]taskmanager.Change, error) {
+	changes := make([]taskmanager.Change, 0, len(entries))
+	for _, entry := range entries {
+		existing, err := s.client.Get(ctx, entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, taskmanager.Change{Action: action(entry, existing), Kind: KindKey, Name: entry.Key})
+	}
+	return changes, nil
+}
+
+// Apply writes entries using check-and-set on ModifyIndex, so values changed concurrently are never overwritten blindly.
+// Keys written before a failure are returned together with the error.
+func (s *Seeder) Apply(ctx context.Context, entries []Entry) ([]Applied, error) {
+	var applied []Applied
+	for _, entry := range entries {
+		result, err := s.apply(ctx, entry)
+		if err != nil {
+			return applied, err
+		}
+		if result != nil {
+			applied = append(applied, *result)
+		}
+	}
+	return applied, nil
+}
+
+func (s *Seeder) apply(ctx context.Context, entry Entry) (*Applied, error) {
+	for attempt := 0; attempt < casAttempts; attempt++ {
+		existing, err := s.client.Get(ctx, entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		change := taskmanager.Change{Action: action(entry, existing), Kind: KindKey, Name: entry.Key}
+		if change.Action == taskmanager.ActionNone {
+			return nil, nil
+		}
+
+		var modifyIndex uint64
+		if existing != nil {
+			modifyIndex = existing.ModifyIndex
+		}
+		written, err := s.client.CAS(ctx, entry.Key, []byte(entry.Value), modifyIndex)
+		if err != nil {
+			return nil, err
+		}
+		if written {
+			return &Applied{Change: change, Undo: s.undo(entry.Key, existing)}, nil
+		}
+	}
+	return nil, fmt.Errorf("consul key '%s' is modified concurrently, gave up after %d attempts", entry.Key, casAttempts)
+}
+
+// undo deletes the created key or restores the previous value, unless the key was changed after seeding
+func (s *Seeder) undo(key string, previous *Pair) func(context.Context) error {
+	return func(ctx context.Context) error {
+		current, err := s.client.Get(ctx, key)
+		if err != nil || current == nil {
+			return err
+		}
+		var written bool
+		if previous == nil {
+			written, err = s.client.DeleteCAS(ctx, key, current.ModifyIndex)
+		} else {
+			written, err = s.client.CAS(ctx, key, previous.Value, current.ModifyIndex)
+		}
+		if err == nil && !written {
+			err = fmt.Errorf("consul key '%s' is modified concurrently", key)
+		}
+		return err
+	}
+}
+
+func action(entry Entry, existing *Pair) taskmanager.Action {
+	switch {
+	case existing == nil:
+		return taskmanager.ActionCreate
+	case entry.Overwrite && !bytes.Equal(existing.Value, []byte(entry.Value)):
+		return taskmanager.ActionUpdate
+	default:
+		return taskmanager.ActionNone
+	}
+}
@@ -0,0 +1,172 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKV serves Consul KV API with check-and-set semantics
+type fakeKV struct {
+	mutex sync.Mutex
+	index uint64
+	pairs map[string]Pair
+	// beforeCAS is called before every CAS to simulate concurrent writers
+	beforeCAS func(key string)
+	queries   []string
+}
+
+func newFakeKV(t *testing.T, options ...Option) (*fakeKV, *Client) {
+	fake := &fakeKV{pairs: map[string]Pair{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewClient(resty.New(), server.URL, "token", options...)
+}
+
+func (f *fakeKV) put(key, value string) {
+	f.index++
+	f.pairs[key] = Pair{Key: key, Value: []byte(value), CreateIndex: f.index, ModifyIndex: f.index}
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	if r.Method == http.MethodPut && f.beforeCAS != nil {
+		f.beforeCAS(key)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.queries = append(f.queries, r.URL.RawQuery)
+	existing, exists := f.pairs[key]
+	cas, _ := strconv.ParseUint(r.URL.Query().Get("cas"), 10, 64)
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]Pair{existing})
+	case http.MethodPut:
+		if existing.ModifyIndex != cas {
+			_, _ = w.Write([]byte("false"))
+			return
+		}
+		value, _ := io.ReadAll(r.Body)
+		f.put(key, string(value))
+		_, _ = w.Write([]byte("true"))
+	case http.MethodDelete:
+		if existing.ModifyIndex != cas {
+			_, _ = w.Write([]byte("false"))
+			return
+		}
+		delete(f.pairs, key)
+		_, _ = w.Write([]byte("true"))
+	}
+}
+
+func TestParseSeed(t *testing.T) {
+	entries, err := ParseSeed("defaults.yaml", []byte(`
+create:
+  config/${NAMESPACE}/application/feature.enabled: "false"
+overwrite:
+  /logging/${NAMESPACE}/config-server/root: INFO
+`), "cloud-core")
+
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "config/cloud-core/application/feature.enabled", Value: "false"},
+		{Key: "logging/cloud-core/config-server/root", Value: "INFO", Overwrite: true},
+	}, entries)
+
+	_, err = ParseSeed("dup.yaml", []byte("create:\n  a: x\noverwrite:\n  a: y\n"), "ns")
+	assert.ErrorContains(t, err, "key 'a' is declared twice")
+	_, err = ParseSeed("unknown.yaml", []byte("keys:\n  a: x\n"), "ns")
+	assert.Error(t, err)
+}
+
+func TestSeeder_ApplyCreateAndOverwrite(t *testing.T) {
+	fake, client := newFakeKV(t)
+	fake.put("config/ns/kept", "manual")
+	fake.put("logging/ns/root", "DEBUG")
+	fake.put("logging/ns/same", "INFO")
+	entries := []Entry{
+		{Key: "config/ns/kept", Value: "default"},
+		{Key: "config/ns/new", Value: "default"},
+		{Key: "logging/ns/root", Value: "INFO", Overwrite: true},
+		{Key: "logging/ns/same", Value: "INFO", Overwrite: true},
+	}
+	seeder := NewSeeder(client)
+
+	planned, err := seeder.Plan(context.Background(), entries)
+	require.NoError(t, err)
+	applied, err := seeder.Apply(context.Background(), entries)
+	require.NoError(t, err)
+
+	assert.Equal(t, []taskmanager.Action{taskmanager.ActionNone, taskmanager.ActionCreate, taskmanager.ActionUpdate, taskmanager.ActionNone},
+		[]taskmanager.Action{planned[0].Action, planned[1].Action, planned[2].Action, planned[3].Action})
+	require.Len(t, applied, 2)
+	assert.Equal(t, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindKey, Name: "config/ns/new"}, applied[0].Change)
+	assert.Equal(t, taskmanager.Change{Action: taskmanager.ActionUpdate, Kind: KindKey, Name: "logging/ns/root"}, applied[1].Change)
+	assert.Equal(t, "manual", string(fake.pairs["config/ns/kept"].Value))
+	assert.Equal(t, "INFO", string(fake.pairs["logging/ns/root"].Value))
+
+	for i := len(applied) - 1; i >= 0; i-- {
+		require.NoError(t, applied[i].Undo(context.Background()))
+	}
+	assert.NotContains(t, fake.pairs, "config/ns/new")
+	assert.Equal(t, "DEBUG", string(fake.pairs["logging/ns/root"].Value))
+}
+
+func TestSeeder_CreateDoesNotOverwriteConcurrentWrite(t *testing.T) {
+	fake, client := newFakeKV(t)
+	fake.beforeCAS = func(key string) {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		if _, exists := fake.pairs[key]; !exists {
+			fake.put(key, "concurrent")
+		}
+	}
+
+	applied, err := NewSeeder(client).Apply(context.Background(), []Entry{{Key: "config/ns/key", Value: "default"}})
+
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Equal(t, "concurrent", string(fake.pairs["config/ns/key"].Value))
+}
+
+func TestSeeder_GivesUpOnPermanentConflict(t *testing.T) {
+	fake, client := newFakeKV(t)
+	fake.put("logging/ns/root", "DEBUG")
+	fake.beforeCAS = func(key string) {
+		fake.mutex.Lock()
+		defer fake.mutex.Unlock()
+		fake.put(key, "WARN")
+	}
+
+	_, err := NewSeeder(client).Apply(context.Background(), []Entry{{Key: "logging/ns/root", Value: "INFO", Overwrite: true}})
+
+	assert.ErrorContains(t, err, "modified concurrently")
+}
+
+func TestScopedClient(t *testing.T) {
+	fake, client := newFakeKV(t, WithNamespace("team-a"), WithPartition("cloud-core"))
+
+	written, err := client.CAS(context.Background(), "config/ns/key", []byte("value"), 0)
+
+	require.NoError(t, err)
+	assert.True(t, written)
+	assert.Contains(t, fake.queries[0], "ns=team-a")
+	assert.Contains(t, fake.queries[0], "partition=cloud-core")
+	assert.Contains(t, fake.queries[0], "cas=0")
+}
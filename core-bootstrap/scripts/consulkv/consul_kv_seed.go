@@ -0,0 +1,176 @@
+package consulkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul/kv"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+)
+
+const TaskName = "consulkv"
+
+var logger = logging.GetLogger("consulkv")
+
+// Configurer seeds Consul KV with default configuration and logging levels from seed documents
+// of a mounted directory (*.yaml, *.yml and *.json files) or of a ConfigMap (every data entry)
+type Configurer struct {
+	Namespace        string
+	consulConfigurer *consul.Configurer
+	seedDir          string
+	seedConfigMap    string
+	undoLog          utils.UndoLog
+}
+
+func New(consulConfigurer *consul.Configurer) *Configurer {
+	return &Configurer{consulConfigurer: consulConfigurer}
+}
+
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
+// DependsOn makes KV seeded with consul configurer already configured
+func (c *Configurer) DependsOn() []string {
+	return []string{consul.TaskName}
+}
+
+func (c *Configurer) Configure(accessor func(string) string) error {
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.seedDir = reader.Optional("CONSUL_KV_SEED_DIR")
+	c.seedConfigMap = reader.Optional("CONSUL_KV_SEED_CONFIGMAP")
+	return reader.Err()
+}
+
+func (c *Configurer) Execute(ctx context.Context) error {
+	entries, err := c.entries(ctx)
+	if err != nil || entries == nil {
+		return err
+	}
+
+	logger.InfoC(ctx, "*** Starting consul KV seeding of %d keys ***", len(entries))
+	applied, err := kv.NewSeeder(c.consulConfigurer.KVClient()).Apply(ctx, entries)
+	for _, result := range applied {
+		logger.InfoC(ctx, "Consul key '%s' is %sd", result.Change.Name, result.Change.Action)
+		taskmanager.RecordChange(ctx, result.Change)
+		c.undoLog.Add(fmt.Sprintf("revert consul key '%s'", result.Change.Name), result.Undo)
+	}
+	if err != nil {
+		return utils.LogError(logger, ctx, "error seeding consul KV: %w", err)
+	}
+
+	logger.InfoC(ctx, "### Finished consul KV seeding: %d of %d keys changed ***", len(applied), len(entries))
+	return nil
+}
+
+// Rollback deletes created keys and restores overwritten values unless they were changed after seeding
+func (c *Configurer) Rollback(ctx context.Context) error {
+	return c.undoLog.Rollback(ctx)
+}
+
+// Inputs returns seed documents, so the task is rerun once they change even if CONSUL_KV_SEED_* values do not
+func (c *Configurer) Inputs(ctx context.Context) (map[string][]byte, error) {
+	if !c.consulConfigurer.Enabled || (c.seedDir == "" && c.seedConfigMap == "") {
+		return nil, nil
+	}
+	return c.loadDocuments(ctx)
+}
+
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	entries, err := c.entries(ctx)
+	if err != nil || entries == nil {
+		return nil, err
+	}
+	return kv.NewSeeder(c.consulConfigurer.KVClient()).Plan(ctx, entries)
+}
+
+// entries returns nil if consul is disabled or no seed source is configured
+func (c *Configurer) entries(ctx context.Context) ([]kv.Entry, error) {
+	if !c.consulConfigurer.Enabled {
+		logger.InfoC(ctx, "CONSUL_ENABLED is not set. Skip consul KV seeding")
+		return nil, nil
+	}
+	if c.seedDir == "" && c.seedConfigMap == "" {
+		logger.InfoC(ctx, "Neither CONSUL_KV_SEED_DIR nor CONSUL_KV_SEED_CONFIGMAP is set. Skip consul KV seeding")
+		return nil, nil
+	}
+
+	documents, err := c.loadDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := []kv.Entry{}
+	sources := make(map[string]string)
+	var errs []error
+	for _, name := range sortedKeys(documents) {
+		parsed, err := kv.ParseSeed(name, documents[name], c.Namespace)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, entry := range parsed {
+			if source, exists := sources[entry.Key]; exists {
+				errs = append(errs, fmt.Errorf("consul key '%s' is declared in both '%s' and '%s'", entry.Key, source, name))
+				continue
+			}
+			sources[entry.Key] = name
+			entries = append(entries, entry)
+		}
+	}
+	return entries, errors.Join(errs...)
+}
+
+func (c *Configurer) loadDocuments(ctx context.Context) (map[string][]byte, error) {
+	documents := make(map[string][]byte)
+	if c.seedDir != "" {
+		files, err := os.ReadDir(c.seedDir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading consul KV seed directory: %w", err)
+		}
+		for _, file := range files {
+			extension := strings.ToLower(filepath.Ext(file.Name()))
+			// mounted ConfigMap volumes contain hidden `..data' links next to the files
+			if file.IsDir() || strings.HasPrefix(file.Name(), ".") || (extension != ".yaml" && extension != ".yml" && extension != ".json") {
+				continue
+			}
+			path := filepath.Join(c.seedDir, file.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading consul KV seed: %w", err)
+			}
+			documents[path] = data
+		}
+	}
+	if c.seedConfigMap != "" {
+		configMap, err := utils.GetExistingConfigMap(ctx, c.Namespace, c.seedConfigMap)
+		if err != nil {
+			return nil, utils.LogError(logger, ctx, "error reading consul KV seed config map: %w", err)
+		}
+		if configMap == nil {
+			return nil, fmt.Errorf("consul KV seed config map '%s' not found", c.seedConfigMap)
+		}
+		for key, data := range configMap.Data {
+			documents[c.seedConfigMap+"/"+key] = []byte(data)
+		}
+	}
+	return documents, nil
+}
+
+func sortedKeys(documents map[string][]byte) []string {
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
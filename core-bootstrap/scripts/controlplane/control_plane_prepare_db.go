@@ -2,32 +2,57 @@ package controlplane
 
 import (
 	"context"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 )
 
-const CpDbCredentialsSecret = "control-plane-db-credentials"
+const (
+	TaskName              = "controlplane"
+	CpDbCredentialsSecret = "control-plane-db-credentials"
+	// Microservice owns the control-plane database in DBaaS
+	Microservice = "control-plane"
+)
 
 var logger = logging.GetLogger("config-server")
 
 type ControlPlaneConfigurer struct {
 	Namespace             string
-	databaseCreate        func(context.Context, string, string, map[string]string) error
+	dbaasConfigurer       *dbaas.Configurer
 	cpDbCredentialsSecret string
+	undoLog               utils.UndoLog
 }
 
-func New(databaseCreate func(context.Context, string, string, map[string]string) error) *ControlPlaneConfigurer {
-	return &ControlPlaneConfigurer{databaseCreate: databaseCreate}
+func New(dbaasConfigurer *dbaas.Configurer) *ControlPlaneConfigurer {
+	return &ControlPlaneConfigurer{dbaasConfigurer: dbaasConfigurer}
 }
 
-func (c *ControlPlaneConfigurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
-	c.cpDbCredentialsSecret = accessor("DB_CREDENTIALS_SECRET")
-	if c.cpDbCredentialsSecret == "" {
-		c.cpDbCredentialsSecret = CpDbCredentialsSecret
+// SecretName returns the name of control-plane database credentials secret set by DB_CREDENTIALS_SECRET
+func SecretName(reader *configsource.Reader) string {
+	if name := reader.Optional("DB_CREDENTIALS_SECRET"); name != "" {
+		return name
 	}
+	return CpDbCredentialsSecret
+}
 
-	return nil
+func (c *ControlPlaneConfigurer) Name() string {
+	return TaskName
+}
+
+// DependsOn makes control-plane database created only after dbaas balancing rules are applied
+func (c *ControlPlaneConfigurer) DependsOn() []string {
+	return []string{dbaas.TaskName}
+}
+
+func (c *ControlPlaneConfigurer) Configure(accessor func(string) string) error {
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.cpDbCredentialsSecret = SecretName(reader)
+
+	return reader.Err()
 }
 
 func (c *ControlPlaneConfigurer) Execute(ctx context.Context) error {
@@ -38,7 +63,7 @@ func (c *ControlPlaneConfigurer) Execute(ctx context.Context) error {
 	namingMapper["dbport"] = "port"
 	namingMapper["dbname"] = "database"
 
-	err := c.databaseCreate(ctx, "control-plane", c.cpDbCredentialsSecret, namingMapper)
+	err := c.dbaasConfigurer.CreateDatabase(ctx, &c.undoLog, Microservice, c.cpDbCredentialsSecret, namingMapper)
 	if err != nil {
 		return utils.LogError(logger, ctx, "Error getting db properties for cp: %v", err)
 	}
@@ -46,3 +71,21 @@ func (c *ControlPlaneConfigurer) Execute(ctx context.Context) error {
 	logger.InfoC(ctx, "### Finished control_plane_prepare_db ***")
 	return nil
 }
+
+// Rollback restores control-plane database credentials overwritten by Execute, the database is kept
+func (c *ControlPlaneConfigurer) Rollback(ctx context.Context) error {
+	return c.undoLog.Rollback(ctx)
+}
+
+// Plan reads the secret from the sink of dbaas task, which Execute writes to
+func (c *ControlPlaneConfigurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	sink := c.dbaasConfigurer.Sink()
+	existing, err := sink.Read(ctx, c.cpDbCredentialsSecret)
+	if err != nil {
+		return nil, err
+	}
+	return []taskmanager.Change{
+		{Action: taskmanager.ActionApply, Kind: dbaas.KindDatabase, Name: Microservice},
+		{Action: taskmanager.CreateOrUpdate(existing != nil), Kind: sink.Kind(), Name: c.cpDbCredentialsSecret, Detail: credentials.DriftDetail(existing)},
+	}, nil
+}
@@ -0,0 +1,115 @@
+package databases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/scripts/controlplane"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/database"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	TaskName           = "databases"
+	DefaultConcurrency = 4
+)
+
+var logger = logging.GetLogger("databases")
+
+// Configurer provisions databases declared by DBAAS_DATABASES through DBaaS, one credentials secret per database.
+// At most DBAAS_DATABASES_CONCURRENCY databases are provisioned at the same time.
+type Configurer struct {
+	Namespace       string
+	dbaasConfigurer *dbaas.Configurer
+	specs           []database.Spec
+	concurrency     int
+	undoLog         utils.UndoLog
+}
+
+func New(dbaasConfigurer *dbaas.Configurer) *Configurer {
+	return &Configurer{dbaasConfigurer: dbaasConfigurer}
+}
+
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
+// DependsOn makes databases created only after dbaas balancing rules are applied
+func (c *Configurer) DependsOn() []string {
+	return []string{dbaas.TaskName}
+}
+
+func (c *Configurer) Configure(accessor func(string) string) error {
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.concurrency = reader.PositiveInt("DBAAS_DATABASES_CONCURRENCY", DefaultConcurrency)
+
+	c.specs = nil
+	if raw := reader.Optional("DBAAS_DATABASES"); raw != "" {
+		specs, err := database.ParseSpecs(raw)
+		if err == nil {
+			// controlplane task provisions its database at the same time
+			err = database.CheckUnique(specs, database.Spec{Microservice: controlplane.Microservice, SecretName: controlplane.SecretName(reader)})
+		}
+		reader.Check("DBAAS_DATABASES", err)
+		c.specs = specs
+	}
+	return reader.Err()
+}
+
+// Execute provisions all declared databases, a failed database does not stop provisioning of others
+func (c *Configurer) Execute(ctx context.Context) error {
+	if len(c.specs) == 0 {
+		logger.InfoC(ctx, "No DBAAS_DATABASES found, skipping databases provisioning")
+		return nil
+	}
+	logger.InfoC(ctx, "Provisioning %d databases, at most %d at a time", len(c.specs), c.concurrency)
+
+	errs := make([]error, len(c.specs))
+	var group errgroup.Group
+	group.SetLimit(c.concurrency)
+	for i, spec := range c.specs {
+		group.Go(func() error {
+			if err := c.dbaasConfigurer.ProvisionDatabase(ctx, &c.undoLog, spec); err != nil {
+				errs[i] = utils.LogError(logger, ctx, "Error provisioning %s database of `%s': %w", spec.DatabaseType(), spec.Microservice, err)
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("error provisioning databases: %w", err)
+	}
+	logger.InfoC(ctx, "All %d databases are provisioned", len(c.specs))
+	return nil
+}
+
+// Rollback restores credentials of declared databases overwritten by Execute, the databases are kept
+func (c *Configurer) Rollback(ctx context.Context) error {
+	return c.undoLog.Rollback(ctx)
+}
+
+// Plan reads the secrets from the sink of dbaas task, which Execute writes to
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	sink := c.dbaasConfigurer.Sink()
+	var changes []taskmanager.Change
+	for _, spec := range c.specs {
+		existing, err := sink.Read(ctx, spec.SecretName)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes,
+			taskmanager.Change{Action: taskmanager.ActionApply, Kind: dbaas.KindDatabase, Name: spec.Microservice, Detail: string(spec.DatabaseType())},
+			taskmanager.Change{Action: taskmanager.CreateOrUpdate(existing != nil), Kind: sink.Kind(), Name: spec.SecretName, Detail: credentials.DriftDetail(existing)},
+		)
+	}
+	return changes, nil
+}
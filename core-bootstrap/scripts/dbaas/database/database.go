@@ -0,0 +1,222 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+type Type string
+
+const (
+	PostgreSQL Type = "postgresql"
+	MongoDB    Type = "mongodb"
+	OpenSearch Type = "opensearch"
+	Cassandra  Type = "cassandra"
+	ClickHouse Type = "clickhouse"
+)
+
+// reservedClassifierKeys are always set from namespace and microservice of the spec
+var reservedClassifierKeys = []string{"namespace", "microserviceName"}
+
+// Spec describes a database to provision in DBaaS and the secret to store its credentials in
+type Spec struct {
+	Microservice string `json:"microservice"`
+	// Type defaults to postgresql
+	Type       Type   `json:"type,omitempty"`
+	SecretName string `json:"secretName"`
+	// NamingMapper renames keys of the secret layout of the type, e.g. `dbhostname: host'
+	NamingMapper map[string]string `json:"namingMapper,omitempty"`
+	// Classifier holds extra classifier fields, `scope' defaults to `service'
+	Classifier         map[string]string      `json:"classifier,omitempty"`
+	DbNamePrefix       string                 `json:"dbNamePrefix,omitempty"`
+	PhysicalDatabaseID string                 `json:"physicalDatabaseId,omitempty"`
+	BackupDisabled     bool                   `json:"backupDisabled,omitempty"`
+	Settings           map[string]interface{} `json:"settings,omitempty"`
+}
+
+// CreateRequest is the body of DBaaS v3 get-or-create database request
+type CreateRequest struct {
+	Classifier         map[string]string      `json:"classifier"`
+	Type               Type                   `json:"type"`
+	OriginService      string                 `json:"originService"`
+	NamePrefix         string                 `json:"namePrefix,omitempty"`
+	PhysicalDatabaseID string                 `json:"physicalDatabaseId,omitempty"`
+	BackupDisabled     bool                   `json:"backupDisabled,omitempty"`
+	Settings           map[string]interface{} `json:"settings,omitempty"`
+}
+
+// Response is the part of DBaaS database response used to fill the secret, connection properties depend on the type
+type Response struct {
+	Name                 string                 `json:"name"`
+	ConnectionProperties map[string]interface{} `json:"connectionProperties"`
+}
+
+// ParseType returns the supported database type of the name, case-insensitive
+func ParseType(name string) (Type, error) {
+	t := Type(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := layouts[t]; !ok {
+		return "", fmt.Errorf("unsupported database type '%s', expected one of: %s", name, strings.Join(supportedTypes(), ", "))
+	}
+	return t, nil
+}
+
+func supportedTypes() []string {
+	types := make([]string, 0, len(layouts))
+	for t := range layouts {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ParseSpecs reads a YAML or JSON list of specs, every spec is validated and specs must be unique, see CheckUnique.
+// Types of parsed specs are normalized, e.g. `MongoDB' and empty type become `mongodb' and `postgresql'.
+func ParseSpecs(data string) ([]Spec, error) {
+	var specs []Spec
+	if err := yaml.UnmarshalStrict([]byte(data), &specs); err != nil {
+		return nil, err
+	}
+
+	for i := range specs {
+		if err := specs[i].Validate(); err != nil {
+			return nil, fmt.Errorf("database #%d: %w", i+1, err)
+		}
+		specs[i].Type, _ = ParseType(string(specs[i].DatabaseType()))
+	}
+	if err := CheckUnique(specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// CheckUnique fails if two specs store credentials in the same secret or provision the same database, i.e. the database
+// of the same type and classifier. Reserved specs are provisioned by other tasks running at the same time, e.g.
+// the control-plane database, and are not checked against each other.
+func CheckUnique(specs []Spec, reserved ...Spec) error {
+	secrets := make(map[string]string, len(specs)+len(reserved))
+	databases := make(map[string]string, len(specs)+len(reserved))
+	for _, spec := range reserved {
+		owner := fmt.Sprintf("%s database of `%s'", spec.DatabaseType(), spec.Microservice)
+		secrets[spec.SecretName] = owner
+		databases[spec.databaseKey()] = owner
+	}
+	for i, spec := range specs {
+		if owner, ok := secrets[spec.SecretName]; ok {
+			return fmt.Errorf("database #%d: secret '%s' is used by %s", i+1, spec.SecretName, owner)
+		}
+		if owner, ok := databases[spec.databaseKey()]; ok {
+			return fmt.Errorf("database #%d: %s database of `%s' has the same type and classifier as %s", i+1, spec.DatabaseType(), spec.Microservice, owner)
+		}
+		secrets[spec.SecretName] = "another database"
+		databases[spec.databaseKey()] = "another database"
+	}
+	return nil
+}
+
+// databaseKey identifies the database of the spec in DBaaS by its type and classifier
+func (s Spec) databaseKey() string {
+	request := s.Request("")
+	keys := make([]string, 0, len(request.Classifier))
+	for key, value := range request.Classifier {
+		keys = append(keys, key+"="+value)
+	}
+	sort.Strings(keys)
+	return string(request.Type) + ":" + strings.Join(keys, ",")
+}
+
+// DatabaseType returns the type of the spec, postgresql if not set
+func (s Spec) DatabaseType() Type {
+	if s.Type == "" {
+		return PostgreSQL
+	}
+	return s.Type
+}
+
+// Validate checks the spec has a microservice, a secret name, a supported type and no reserved classifier fields
+func (s Spec) Validate() error {
+	if s.Microservice == "" {
+		return fmt.Errorf("database microservice is empty")
+	}
+	if s.SecretName == "" {
+		return fmt.Errorf("database secret name of microservice '%s' is empty", s.Microservice)
+	}
+	if _, err := ParseType(string(s.DatabaseType())); err != nil {
+		return fmt.Errorf("database of microservice '%s': %w", s.Microservice, err)
+	}
+	for _, key := range reservedClassifierKeys {
+		if _, ok := s.Classifier[key]; ok {
+			return fmt.Errorf("database of microservice '%s': classifier field '%s' cannot be overridden", s.Microservice, key)
+		}
+	}
+	return nil
+}
+
+// Request builds the get-or-create request of the spec for the namespace
+func (s Spec) Request(namespace string) CreateRequest {
+	classifier := map[string]string{"scope": "service"}
+	for key, value := range s.Classifier {
+		classifier[key] = value
+	}
+	classifier["namespace"] = namespace
+	classifier["microserviceName"] = s.Microservice
+
+	return CreateRequest{
+		Classifier:         classifier,
+		Type:               s.DatabaseType(),
+		OriginService:      s.Microservice,
+		NamePrefix:         s.DbNamePrefix,
+		PhysicalDatabaseID: s.PhysicalDatabaseID,
+		BackupDisabled:     s.BackupDisabled,
+		Settings:           s.Settings,
+	}
+}
+
+// SecretData maps connection properties of the response to keys of the type's secret layout renamed by NamingMapper.
+// Optional properties missing in the response are stored as empty values.
+func (s Spec) SecretData(response Response) (map[string][]byte, error) {
+	layout := layouts[s.DatabaseType()]
+	for _, property := range layout.required {
+		if formatProperty(response.ConnectionProperties[property]) == "" {
+			return nil, fmt.Errorf("%s connection property '%s' is missing in DBaaS response", s.DatabaseType(), property)
+		}
+	}
+
+	data := make(map[string][]byte, len(layout.keys))
+	for _, key := range layout.keys {
+		data[MapKey(key.secretKey, s.NamingMapper)] = []byte(formatProperty(response.ConnectionProperties[key.property]))
+	}
+	return data, nil
+}
+
+// MapKey returns the name of the secret key given by namingMapper, the key itself if it is not mapped
+func MapKey(name string, namingMapper map[string]string) string {
+	if newName, ok := namingMapper[name]; ok {
+		return newName
+	}
+	return name
+}
+
+func formatProperty(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatProperty(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeResponse(t *testing.T, body string) Response {
+	var response Response
+	require.NoError(t, json.Unmarshal([]byte(body), &response))
+	return response
+}
+
+func TestParseType(t *testing.T) {
+	dbType, err := ParseType(" MongoDB ")
+	require.NoError(t, err)
+	assert.Equal(t, MongoDB, dbType)
+
+	_, err = ParseType("redis")
+	assert.ErrorContains(t, err, "unsupported database type 'redis', expected one of: cassandra, clickhouse, mongodb, opensearch, postgresql")
+}
+
+func TestSpec_Validate(t *testing.T) {
+	assert.NoError(t, Spec{Microservice: "control-plane", SecretName: "cp-db"}.Validate())
+	assert.ErrorContains(t, Spec{SecretName: "cp-db"}.Validate(), "microservice is empty")
+	assert.ErrorContains(t, Spec{Microservice: "control-plane"}.Validate(), "secret name of microservice 'control-plane' is empty")
+	assert.ErrorContains(t, Spec{Microservice: "ms", SecretName: "s", Type: "redis"}.Validate(), "unsupported database type")
+	assert.ErrorContains(t, Spec{Microservice: "ms", SecretName: "s", Classifier: map[string]string{"namespace": "other"}}.Validate(),
+		"classifier field 'namespace' cannot be overridden")
+}
+
+func TestSpec_Request(t *testing.T) {
+	spec := Spec{
+		Microservice:       "tenant-manager",
+		Type:               MongoDB,
+		Classifier:         map[string]string{"scope": "tenant", "tenantId": "t1"},
+		DbNamePrefix:       "tm",
+		PhysicalDatabaseID: "mongo-1",
+		BackupDisabled:     true,
+		Settings:           map[string]interface{}{"pgExtensions": []string{"pg_trgm"}},
+	}
+
+	body, err := json.Marshal(spec.Request("core"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"classifier": {"namespace": "core", "microserviceName": "tenant-manager", "scope": "tenant", "tenantId": "t1"},
+		"type": "mongodb",
+		"originService": "tenant-manager",
+		"namePrefix": "tm",
+		"physicalDatabaseId": "mongo-1",
+		"backupDisabled": true,
+		"settings": {"pgExtensions": ["pg_trgm"]}
+	}`, string(body))
+}
+
+func TestSpec_Request_Defaults(t *testing.T) {
+	body, err := json.Marshal(Spec{Microservice: "control-plane"}.Request("core"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"classifier": {"namespace": "core", "microserviceName": "control-plane", "scope": "service"},
+		"type": "postgresql",
+		"originService": "control-plane"
+	}`, string(body))
+}
+
+func TestSpec_SecretData_PostgreSQL(t *testing.T) {
+	response := decodeResponse(t, `{"name": "cp", "connectionProperties": {
+		"host": "pg.postgres", "port": 5432, "name": "cp_db", "url": "jdbc:postgresql://pg.postgres:5432/cp_db",
+		"username": "user", "password": "secret", "role": "admin", "tls": false}}`)
+
+	data, err := Spec{Microservice: "control-plane", NamingMapper: map[string]string{"dbhostname": "host"}}.SecretData(response)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"host":     []byte("pg.postgres"),
+		"dbport":   []byte("5432"),
+		"role":     []byte("admin"),
+		"dbname":   []byte("cp_db"),
+		"url":      []byte("jdbc:postgresql://pg.postgres:5432/cp_db"),
+		"username": []byte("user"),
+		"password": []byte("secret"),
+		"tls":      []byte("false"),
+	}, data)
+}
+
+func TestSpec_SecretData_Cassandra(t *testing.T) {
+	response := decodeResponse(t, `{"connectionProperties": {
+		"contactPoints": ["c1.cassandra", "c2.cassandra"], "port": 9042, "keyspace": "ks",
+		"username": "user", "password": "secret"}}`)
+
+	data, err := Spec{Microservice: "ms", Type: Cassandra}.SecretData(response)
+	require.NoError(t, err)
+	assert.Equal(t, "c1.cassandra,c2.cassandra", string(data["contactpoints"]))
+	assert.Equal(t, "ks", string(data["keyspace"]))
+	assert.Equal(t, "9042", string(data["dbport"]))
+	assert.Empty(t, data["url"])
+	assert.Contains(t, data, "tls")
+}
+
+func TestSpec_SecretData_MissingRequired(t *testing.T) {
+	response := decodeResponse(t, `{"connectionProperties": {"url": "http://opensearch:9200", "username": "user"}}`)
+
+	_, err := Spec{Microservice: "ms", Type: OpenSearch}.SecretData(response)
+	assert.EqualError(t, err, "opensearch connection property 'password' is missing in DBaaS response")
+}
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs(`
+- microservice: tenant-manager
+  type: MongoDB
+  secretName: tenant-manager-db-credentials
+  classifier:
+    scope: tenant
+- microservice: site-management
+  secretName: site-management-db-credentials
+  namingMapper:
+    dbhostname: host
+  backupDisabled: true
+`)
+	require.NoError(t, err)
+	require.Len(t, specs, 2)
+	assert.Equal(t, MongoDB, specs[0].Type)
+	assert.Equal(t, map[string]string{"scope": "tenant"}, specs[0].Classifier)
+	assert.Equal(t, PostgreSQL, specs[1].Type)
+	assert.Equal(t, "host", specs[1].NamingMapper["dbhostname"])
+	assert.True(t, specs[1].BackupDisabled)
+}
+
+func TestParseSpecs_Invalid(t *testing.T) {
+	_, err := ParseSpecs(`[{"microservice": "a", "secretName": "s", "unknown": 1}]`)
+	assert.ErrorContains(t, err, "unknown")
+
+	_, err = ParseSpecs(`[{"microservice": "a", "secretName": "s"}, {"microservice": "b", "secretName": "s"}]`)
+	assert.EqualError(t, err, "database #2: secret 's' is used by another database")
+
+	_, err = ParseSpecs(`[{"microservice": "a", "secretName": "s", "type": "redis"}]`)
+	assert.ErrorContains(t, err, "database #1: database of microservice 'a': unsupported database type 'redis'")
+}
+
+func TestCheckUnique(t *testing.T) {
+	controlPlane := Spec{Microservice: "control-plane", SecretName: "control-plane-db-credentials"}
+	tenantManager := Spec{Microservice: "tenant-manager", SecretName: "tenant-manager-db-credentials"}
+	assert.NoError(t, CheckUnique([]Spec{tenantManager}, controlPlane))
+	assert.NoError(t, CheckUnique([]Spec{{Microservice: "control-plane", Type: MongoDB, SecretName: "cp-mongo"}}, controlPlane),
+		"database of another type")
+	assert.NoError(t, CheckUnique([]Spec{{Microservice: "control-plane", SecretName: "cp-tenant", Classifier: map[string]string{"scope": "tenant"}}}, controlPlane),
+		"database with another classifier")
+
+	err := CheckUnique([]Spec{tenantManager, {Microservice: "site-management", SecretName: "control-plane-db-credentials"}}, controlPlane)
+	assert.EqualError(t, err, "database #2: secret 'control-plane-db-credentials' is used by postgresql database of `control-plane'")
+
+	err = CheckUnique([]Spec{{Microservice: "control-plane", SecretName: "cp", Classifier: map[string]string{"scope": "service"}}}, controlPlane)
+	assert.EqualError(t, err, "database #1: postgresql database of `control-plane' has the same type and classifier as postgresql database of `control-plane'")
+
+	err = CheckUnique([]Spec{tenantManager, {Microservice: "tenant-manager", Type: PostgreSQL, SecretName: "other"}})
+	assert.EqualError(t, err, "database #2: postgresql database of `tenant-manager' has the same type and classifier as another database")
+}
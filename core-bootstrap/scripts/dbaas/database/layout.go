@@ -0,0 +1,82 @@
+package database
+
+// secretKey maps a connection property of DBaaS response to a key of the credentials secret
+type secretKey struct {
+	secretKey string
+	property  string
+}
+
+type layout struct {
+	keys []secretKey
+	// required properties must be present in the response, the secret is not written otherwise
+	required []string
+}
+
+// layouts are secret key layouts of supported types, postgresql layout is the one used by control-plane
+var layouts = map[Type]layout{
+	PostgreSQL: {
+		keys: []secretKey{
+			{"dbhostname", "host"},
+			{"dbport", "port"},
+			{"role", "role"},
+			{"dbname", "name"},
+			{"url", "url"},
+			{"username", "username"},
+			{"password", "password"},
+			{"tls", "tls"},
+		},
+		required: []string{"host", "username", "password"},
+	},
+	MongoDB: {
+		keys: []secretKey{
+			{"dbhostname", "host"},
+			{"dbport", "port"},
+			{"role", "role"},
+			{"dbname", "authDbName"},
+			{"url", "url"},
+			{"username", "username"},
+			{"password", "password"},
+			{"tls", "tls"},
+		},
+		required: []string{"url", "username", "password"},
+	},
+	OpenSearch: {
+		keys: []secretKey{
+			{"dbhostname", "host"},
+			{"dbport", "port"},
+			{"role", "role"},
+			{"prefix", "resourcePrefix"},
+			{"url", "url"},
+			{"username", "username"},
+			{"password", "password"},
+			{"tls", "tls"},
+		},
+		required: []string{"url", "username", "password"},
+	},
+	Cassandra: {
+		keys: []secretKey{
+			{"contactpoints", "contactPoints"},
+			{"dbport", "port"},
+			{"role", "role"},
+			{"keyspace", "keyspace"},
+			{"url", "url"},
+			{"username", "username"},
+			{"password", "password"},
+			{"tls", "tls"},
+		},
+		required: []string{"contactPoints", "keyspace", "username", "password"},
+	},
+	ClickHouse: {
+		keys: []secretKey{
+			{"dbhostname", "host"},
+			{"dbport", "port"},
+			{"role", "role"},
+			{"dbname", "name"},
+			{"url", "url"},
+			{"username", "username"},
+			{"password", "password"},
+			{"tls", "tls"},
+		},
+		required: []string{"host", "username", "password"},
+	},
+}
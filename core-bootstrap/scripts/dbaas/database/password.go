@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+)
+
+const (
+	// PasswordRotatedAtAnnotation keeps the time of the last password rotation of the credentials secret
+	PasswordRotatedAtAnnotation = "core-bootstrap.qubership.org/password-rotated-at"
+	// PasswordMaxAgeAnnotation set on credentials secret makes the password rotated once it is older than the duration
+	PasswordMaxAgeAnnotation = "core-bootstrap.qubership.org/password-max-age"
+)
+
+// PasswordChangeRequest is the body of DBaaS password change request of the database with the classifier
+type PasswordChangeRequest struct {
+	Classifier map[string]string `json:"classifier"`
+	Type       Type              `json:"type"`
+}
+
+// PasswordChangeResponse lists databases with changed passwords and their new connection properties
+type PasswordChangeResponse struct {
+	Changed []struct {
+		Classifier map[string]string      `json:"classifier"`
+		Connection map[string]interface{} `json:"connection"`
+	} `json:"changed"`
+	Failed []struct {
+		Classifier map[string]string `json:"classifier"`
+		Message    string            `json:"message"`
+	} `json:"failed"`
+}
+
+// PasswordChangeRequest builds the password change request of the database of the spec
+func (s Spec) PasswordChangeRequest(namespace string) PasswordChangeRequest {
+	request := s.Request(namespace)
+	return PasswordChangeRequest{Classifier: request.Classifier, Type: request.Type}
+}
+
+// Connection returns new connection properties of the only changed database, failures reported by DBaaS are errors
+func (r PasswordChangeResponse) Connection() (map[string]interface{}, error) {
+	if len(r.Failed) > 0 {
+		messages := make([]string, 0, len(r.Failed))
+		for _, failed := range r.Failed {
+			messages = append(messages, failed.Message)
+		}
+		return nil, fmt.Errorf("password change failed: %s", strings.Join(messages, "; "))
+	}
+	if len(r.Changed) != 1 {
+		return nil, fmt.Errorf("password change is expected for one database, DBaaS changed %d", len(r.Changed))
+	}
+	return r.Changed[0].Connection, nil
+}
+
+// PasswordRotationDue tells if the password stored in the secret with the annotations and creation time must be
+// rotated: on explicit request not served for the secret yet, see credentials.RotationRequested, or when it is older
+// than the max age, PasswordMaxAgeAnnotation overrides the given max age. The returned reason is logged.
+func PasswordRotationDue(now time.Time, annotations map[string]string, created time.Time, request string, maxAge time.Duration) (bool, string, error) {
+	if credentials.RotationRequested(request, annotations) {
+		return true, fmt.Sprintf("rotation is requested by `%s'", request), nil
+	}
+	if value := annotations[PasswordMaxAgeAnnotation]; value != "" {
+		var err error
+		if maxAge, err = time.ParseDuration(value); err != nil {
+			return false, "", fmt.Errorf("invalid annotation %s: %w", PasswordMaxAgeAnnotation, err)
+		}
+	}
+	if maxAge <= 0 {
+		return false, "", nil
+	}
+
+	rotated := created
+	if value := annotations[PasswordRotatedAtAnnotation]; value != "" {
+		var err error
+		if rotated, err = time.Parse(time.RFC3339, value); err != nil {
+			return false, "", fmt.Errorf("invalid annotation %s: %w", PasswordRotatedAtAnnotation, err)
+		}
+	}
+	if rotated.IsZero() {
+		return false, "", nil
+	}
+	age := now.Sub(rotated)
+	if age < maxAge {
+		return false, "", nil
+	}
+	return true, fmt.Sprintf("password is %s old, which exceeds max age %s", age.Round(time.Second), maxAge), nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_PasswordChangeRequest(t *testing.T) {
+	body, err := json.Marshal(Spec{Microservice: "control-plane", DbNamePrefix: "cp"}.PasswordChangeRequest("core"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"classifier": {"namespace": "core", "microserviceName": "control-plane", "scope": "service"},
+		"type": "postgresql"
+	}`, string(body))
+}
+
+func TestPasswordChangeResponse_Connection(t *testing.T) {
+	var response PasswordChangeResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"changed": [{"classifier": {"microserviceName": "control-plane"},
+		"connection": {"username": "user", "password": "new"}}], "failed": []}`), &response))
+	connection, err := response.Connection()
+	require.NoError(t, err)
+	assert.Equal(t, "new", connection["password"])
+
+	require.NoError(t, json.Unmarshal([]byte(`{"changed": [], "failed": [{"message": "adapter is unavailable"}]}`), &response))
+	_, err = response.Connection()
+	assert.EqualError(t, err, "password change failed: adapter is unavailable")
+
+	_, err = PasswordChangeResponse{}.Connection()
+	assert.EqualError(t, err, "password change is expected for one database, DBaaS changed 0")
+}
+
+func TestPasswordRotationDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	created := now.Add(-48 * time.Hour)
+
+	due, reason, err := PasswordRotationDue(now, nil, created, "true", 0)
+	require.NoError(t, err)
+	assert.True(t, due, "requested")
+	assert.Equal(t, "rotation is requested by `true'", reason)
+
+	rotated := map[string]string{PasswordRotatedAtAnnotation: now.Add(-time.Hour).Format(time.RFC3339)}
+	maps.Copy(rotated, credentials.ServedRotationRequest("true"))
+	due, _, err = PasswordRotationDue(now, rotated, created, "true", 0)
+	require.NoError(t, err)
+	assert.False(t, due, "request is served once")
+	due, _, err = PasswordRotationDue(now, rotated, created, "2025-06-01", 0)
+	require.NoError(t, err)
+	assert.True(t, due, "new request")
+
+	due, _, err = PasswordRotationDue(now, nil, created, "", 0)
+	require.NoError(t, err)
+	assert.False(t, due, "no max age")
+
+	due, reason, err = PasswordRotationDue(now, nil, created, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, due, "older than max age since creation")
+	assert.Equal(t, "password is 48h0m0s old, which exceeds max age 24h0m0s", reason)
+
+	rotatedRecently := map[string]string{PasswordRotatedAtAnnotation: now.Add(-time.Hour).Format(time.RFC3339)}
+	due, _, err = PasswordRotationDue(now, rotatedRecently, created, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, due, "rotated recently")
+
+	rotatedRecently[PasswordMaxAgeAnnotation] = "30m"
+	due, _, err = PasswordRotationDue(now, rotatedRecently, created, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.True(t, due, "annotation overrides max age")
+
+	_, _, err = PasswordRotationDue(now, map[string]string{PasswordRotatedAtAnnotation: "yesterday"}, created, "", time.Hour)
+	assert.ErrorContains(t, err, "invalid annotation "+PasswordRotatedAtAnnotation)
+}
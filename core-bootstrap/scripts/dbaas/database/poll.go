@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Backoff is the polling strategy of asynchronous DBaaS operations: intervals between attempts start at
+// InitialInterval and double up to MaxInterval, the whole operation including the first attempt is limited by Timeout
+type Backoff struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Timeout         time.Duration
+}
+
+var DefaultBackoff = Backoff{InitialInterval: time.Second, MaxInterval: 30 * time.Second, Timeout: 10 * time.Minute}
+
+// TimeoutError is returned when an asynchronous operation is not completed within the polling timeout
+type TimeoutError struct {
+	Operation string
+	Attempts  int
+	Timeout   time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s is not completed within %s after %d attempts", e.Operation, e.Timeout, e.Attempts)
+}
+
+// Poll calls attempt until it reports completion, returns an error or the timeout passes. Attempts get a context
+// bounded by the timeout, cancellation of ctx stops polling with its error.
+func (b Backoff) Poll(ctx context.Context, operation string, attempt func(context.Context) (bool, error)) error {
+	pollCtx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+
+	interval := b.InitialInterval
+	for attempts := 1; ; attempts++ {
+		done, err := attempt(pollCtx)
+		timedOut := ctx.Err() == nil && errors.Is(pollCtx.Err(), context.DeadlineExceeded)
+		if err != nil && !timedOut {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if timedOut {
+			return &TimeoutError{Operation: operation, Attempts: attempts, Timeout: b.Timeout}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-pollCtx.Done():
+			timer.Stop()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return &TimeoutError{Operation: operation, Attempts: attempts, Timeout: b.Timeout}
+		case <-timer.C:
+		}
+		interval = min(2*interval, b.MaxInterval)
+	}
+}
+
+// Validate checks intervals and timeout are positive and the initial interval does not exceed the maximal one
+func (b Backoff) Validate() error {
+	if b.InitialInterval <= 0 || b.MaxInterval <= 0 || b.Timeout <= 0 {
+		return fmt.Errorf("polling intervals and timeout must be positive")
+	}
+	if b.InitialInterval > b.MaxInterval {
+		return fmt.Errorf("initial polling interval %s exceeds maximal interval %s", b.InitialInterval, b.MaxInterval)
+	}
+	return nil
+}
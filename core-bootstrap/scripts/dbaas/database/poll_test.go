@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBackoff = Backoff{InitialInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond, Timeout: time.Second}
+
+func TestBackoff_Poll_CompletesAfterPending(t *testing.T) {
+	attempts := 0
+	err := testBackoff.Poll(context.Background(), "provisioning", func(context.Context) (bool, error) {
+		attempts++
+		return attempts == 5, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, attempts)
+}
+
+func TestBackoff_Poll_StopsOnError(t *testing.T) {
+	attempts := 0
+	err := testBackoff.Poll(context.Background(), "provisioning", func(context.Context) (bool, error) {
+		attempts++
+		return false, errors.New("wrong status")
+	})
+	assert.EqualError(t, err, "wrong status")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestBackoff_Poll_Timeout(t *testing.T) {
+	backoff := Backoff{InitialInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond, Timeout: 30 * time.Millisecond}
+	err := backoff.Poll(context.Background(), "provisioning of db", func(context.Context) (bool, error) {
+		return false, nil
+	})
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, "provisioning of db", timeoutErr.Operation)
+	assert.Greater(t, timeoutErr.Attempts, 1)
+	assert.ErrorContains(t, err, "provisioning of db is not completed within 30ms")
+}
+
+func TestBackoff_Poll_AttemptExceedingTimeout(t *testing.T) {
+	backoff := Backoff{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, Timeout: 10 * time.Millisecond}
+	err := backoff.Poll(context.Background(), "provisioning", func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+
+	var timeoutErr *TimeoutError
+	assert.ErrorAs(t, err, &timeoutErr)
+}
+
+func TestBackoff_Poll_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backoff := Backoff{InitialInterval: time.Hour, MaxInterval: time.Hour, Timeout: 2 * time.Hour}
+	err := backoff.Poll(ctx, "provisioning", func(context.Context) (bool, error) {
+		cancel()
+		return false, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBackoff_Validate(t *testing.T) {
+	assert.NoError(t, DefaultBackoff.Validate())
+	assert.Error(t, Backoff{InitialInterval: time.Second, MaxInterval: time.Second}.Validate())
+	assert.ErrorContains(t, Backoff{InitialInterval: time.Minute, MaxInterval: time.Second, Timeout: time.Hour}.Validate(),
+		"initial polling interval 1m0s exceeds maximal interval 1s")
+}
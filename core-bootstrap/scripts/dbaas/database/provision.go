@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+)
+
+var logger = logging.GetLogger("dbaas")
+
+// Operation is the status of asynchronous DBaaS operation returned by the tracking endpoint from Location header
+type Operation struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Done tells if the tracked operation is completed: it fails for failed operations, tracking responses without
+// status are completed unless they are 202 Accepted
+func (o Operation) Done(statusCode int) (bool, error) {
+	if statusCode == http.StatusAccepted {
+		return false, nil
+	}
+	switch strings.ToUpper(o.Status) {
+	case "FAILED", "ERROR", "TERMINATED", "CANCELED":
+		return false, fmt.Errorf("dbaas operation failed with status %s: %s", o.Status, o.Message)
+	case "NOT_STARTED", "PENDING", "IN_PROGRESS", "RUNNING", "PROCESSING":
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// Provisioner gets or creates databases by DBaaS v3 API polling asynchronous provisioning
+type Provisioner struct {
+	Client   *resty.Client
+	Username string
+	Password string
+	Backoff  Backoff
+}
+
+// GetOrCreate sends the get-or-create request to url and polls DBaaS while it answers 202 Accepted: the tracking
+// endpoint from Location header of the response is polled if present and the request is repeated once the tracked
+// operation is done, so the database is always read by its classifier. The request is repeated while DBaaS answers
+// 202 without Location. It returns the database and whether it was created by the request.
+func (p Provisioner) GetOrCreate(ctx context.Context, url string, request CreateRequest, operation string) (Response, bool, error) {
+	var database Response
+	var trackingURL string
+	accepted, created := false, false
+	err := p.Backoff.Poll(ctx, operation, func(ctx context.Context) (bool, error) {
+		if trackingURL != "" {
+			done, err := p.track(ctx, trackingURL)
+			if err != nil || !done {
+				return false, err
+			}
+			logger.InfoC(ctx, "Tracked dbaas operation is done, reading the database of %s", operation)
+			trackingURL = ""
+		}
+
+		resp, err := p.Client.R().
+			SetContext(ctx).
+			SetBasicAuth(p.Username, p.Password).
+			SetHeader("Content-Type", "application/json").
+			SetBody(request).
+			Put(url)
+		if err != nil {
+			return false, fmt.Errorf("error sending request to dbaas: %w", err)
+		}
+		switch resp.StatusCode() {
+		case http.StatusOK, http.StatusCreated:
+			// the body is decoded only here, 202 Accepted responses may be empty
+			if err := json.Unmarshal(resp.Body(), &database); err != nil {
+				return false, fmt.Errorf("error decoding dbaas response: %w", err)
+			}
+			created = accepted || resp.StatusCode() == http.StatusCreated
+			return true, nil
+		case http.StatusAccepted:
+			accepted = true
+			if location := resp.Header().Get("Location"); location != "" {
+				if trackingURL, err = resolveReference(url, location); err != nil {
+					return false, fmt.Errorf("invalid dbaas operation tracking location '%s': %w", location, err)
+				}
+			}
+			logger.InfoC(ctx, "Got 202 ACCEPTED response, %s is in progress", operation)
+			return false, nil
+		default:
+			return false, fmt.Errorf("wrong dbaas response status: %s, resp: %s", resp.Status(), resp.String())
+		}
+	})
+	if err != nil {
+		return Response{}, false, err
+	}
+	return database, created, nil
+}
+
+// track polls the tracking endpoint once and tells if the operation is done
+func (p Provisioner) track(ctx context.Context, trackingURL string) (bool, error) {
+	resp, err := p.Client.R().
+		SetContext(ctx).
+		SetBasicAuth(p.Username, p.Password).
+		Get(trackingURL)
+	if err != nil {
+		return false, fmt.Errorf("error sending request to dbaas: %w", err)
+	}
+	if resp.IsError() {
+		return false, fmt.Errorf("wrong dbaas operation tracking response status: %s, resp: %s", resp.Status(), resp.String())
+	}
+	var operation Operation
+	// tracking endpoints not returning JSON status are tracked by response status only
+	_ = json.Unmarshal(resp.Body(), &operation)
+	return operation.Done(resp.StatusCode())
+}
+
+// resolveReference resolves the location, which may be relative, against the base URL
+func resolveReference(base, location string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	reference, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(reference).String(), nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDbaas answers get-or-create requests with 202 and Location until the tracked operation reports the statuses
+type fakeDbaas struct {
+	statuses []string
+	puts     int
+	tracks   int
+}
+
+func (f *fakeDbaas) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPut && r.URL.Path == "/api/v3/dbaas/ns/databases":
+		f.puts++
+		if f.tracks < len(f.statuses) {
+			w.Header().Set("Location", "/api/v3/dbaas/ns/operations/42")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":                 "db-1",
+			"connectionProperties": map[string]interface{}{"username": "user", "password": "secret"},
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v3/dbaas/ns/operations/42":
+		status := f.statuses[f.tracks]
+		f.tracks++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"trackingId": "42", "status": status, "message": "adapter " + status})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func provision(t *testing.T, dbaas *fakeDbaas) (Response, bool, error) {
+	server := httptest.NewServer(dbaas)
+	t.Cleanup(server.Close)
+	provisioner := Provisioner{Client: resty.New(), Username: "user", Password: "pass", Backoff: testBackoff}
+	request := CreateRequest{Classifier: map[string]string{"microserviceName": "svc"}, Type: PostgreSQL}
+	return provisioner.GetOrCreate(context.Background(), server.URL+"/api/v3/dbaas/ns/databases", request, "provisioning")
+}
+
+func TestProvisioner_GetOrCreate_ReadsDatabaseAfterTrackedOperation(t *testing.T) {
+	dbaas := &fakeDbaas{statuses: []string{"IN_PROGRESS", "COMPLETED"}}
+	database, created, err := provision(t, dbaas)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "db-1", database.Name)
+	assert.Equal(t, "secret", database.ConnectionProperties["password"])
+	assert.Equal(t, 2, dbaas.tracks)
+	assert.Equal(t, 2, dbaas.puts)
+}
+
+func TestProvisioner_GetOrCreate_FailedOperation(t *testing.T) {
+	dbaas := &fakeDbaas{statuses: []string{"IN_PROGRESS", "FAILED"}}
+	_, _, err := provision(t, dbaas)
+	assert.EqualError(t, err, "dbaas operation failed with status FAILED: adapter FAILED")
+	assert.Equal(t, 1, dbaas.puts)
+}
+
+func TestProvisioner_GetOrCreate_Existing(t *testing.T) {
+	dbaas := &fakeDbaas{}
+	database, created, err := provision(t, dbaas)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "db-1", database.Name)
+	assert.Equal(t, 0, dbaas.tracks)
+}
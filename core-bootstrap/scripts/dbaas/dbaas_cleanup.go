@@ -0,0 +1,91 @@
+package dbaas
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/rules"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// Cleanup is the uninstall task removing per-namespace balancing rules, only if DBAAS_CLEANUP_BALANCING_RULES is true.
+// Databases and on-microservice rules are kept, they are owned by DBaaS namespace lifecycle.
+type Cleanup struct {
+	Configurer
+	enabled bool
+}
+
+func NewCleanup() *Cleanup {
+	return &Cleanup{}
+}
+
+func (c *Cleanup) Configure(accessor func(string) string) error {
+	if err := c.Configurer.Configure(accessor); err != nil {
+		return err
+	}
+	c.enabled = configsource.NewReader(accessor).Boolean("DBAAS_CLEANUP_BALANCING_RULES")
+	return nil
+}
+
+func (c *Cleanup) Execute(ctx context.Context) error {
+	changes, err := c.Plan(ctx)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		url := fmt.Sprintf("%s/api/v3/dbaas/%s/physical_databases/balancing/rules/%s", c.ApiDbaasAddress, c.Namespace, change.Name)
+		logger.InfoC(ctx, "Deleting dbaas balancing rule %s, url: %s", change.Name, url)
+		resp, err := c.httpClient.R().
+			SetContext(ctx).
+			SetBasicAuth(c.Username, c.password).
+			Delete(url)
+		if err != nil {
+			return utils.LogError(logger, ctx, "Error deleting balancing rule %s: %w", change.Name, err)
+		}
+		if resp.StatusCode() == http.StatusNotFound {
+			logger.InfoC(ctx, "Balancing rule %s does not exist", change.Name)
+			continue
+		}
+		if resp.IsError() {
+			return utils.LogError(logger, ctx, "Error deleting balancing rule %s [HTTP status: %d]: %s", change.Name, resp.StatusCode(), resp.String())
+		}
+		taskmanager.RecordChange(ctx, change)
+	}
+	return nil
+}
+
+// Plan reports existing per-namespace rules named like the ones created by the dbaas task or declared by the
+// configuration, including rules which are not declared anymore. Declared rules are reported if DBaaS does not
+// allow to read existing ones.
+func (c *Cleanup) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	if !c.enabled {
+		logger.InfoC(ctx, "DBAAS_CLEANUP_BALANCING_RULES is not true, balancing rules are kept")
+		return nil, nil
+	}
+	declared := c.desiredNamespaceRules()
+	documents, known, err := c.readRules(ctx, c.namespaceRulesURL())
+	if err != nil {
+		return nil, err
+	}
+	names := slices.Collect(maps.Keys(declared))
+	if known {
+		names = nil
+		for name := range rules.IndexByName(documents) {
+			if _, ok := declared[name]; ok || c.isNamespaceRule(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	var changes []taskmanager.Change
+	for _, name := range names {
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindBalancingRule, Name: name})
+	}
+	return changes, nil
+}
@@ -0,0 +1,63 @@
+package dbaas
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/database"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// rotatePasswordIfDue asks DBaaS to change the password of the database if rotation is requested by DBAAS_PASSWORD_ROTATE
+// and the request was not served for the credentials yet, or the password stored in the existing credentials is older
+// than DBAAS_PASSWORD_MAX_AGE (overridden by PasswordMaxAgeAnnotation of the credentials). New connection properties are
+// merged into the response and the returned annotations record the rotation time and the served request; nil
+// annotations mean the password was not rotated.
+func (c *Configurer) rotatePasswordIfDue(ctx context.Context, spec database.Spec, existing *credentials.Credentials, dbResponse *database.Response) (map[string]string, error) {
+	// new credentials are fresh
+	if existing == nil {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	due, reason, err := database.PasswordRotationDue(now, existing.Annotations, existing.Created, c.rotationRequest, c.passwordMaxAge)
+	if err != nil {
+		return nil, utils.LogError(logger, ctx, "Error checking password rotation of %s: %w", spec.SecretName, err)
+	}
+	if !due {
+		return nil, nil
+	}
+
+	logger.InfoC(ctx, "Rotating password of %s database of `%s': %s", spec.DatabaseType(), spec.Microservice, reason)
+	url := fmt.Sprintf("%s/api/v3/dbaas/namespaces/%s/password-changes", c.ApiDbaasAddress, c.Namespace)
+	var changeResponse database.PasswordChangeResponse
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBasicAuth(c.Username, c.password).
+		SetHeader("Content-Type", "application/json").
+		SetBody(spec.PasswordChangeRequest(c.Namespace)).
+		SetResult(&changeResponse).
+		Post(url)
+	if err != nil {
+		return nil, utils.LogError(logger, ctx, "Error sending password change request to dbaas: %w", err)
+	}
+	if resp.IsError() {
+		return nil, utils.LogError(logger, ctx, "Error changing password of `%s' database [HTTP status: %d]: %s", spec.Microservice, resp.StatusCode(), resp.String())
+	}
+	connection, err := changeResponse.Connection()
+	if err != nil {
+		return nil, utils.LogError(logger, ctx, "Error changing password of `%s' database [HTTP status: %d]: %w", spec.Microservice, resp.StatusCode(), err)
+	}
+
+	if dbResponse.ConnectionProperties == nil {
+		dbResponse.ConnectionProperties = make(map[string]interface{})
+	}
+	maps.Copy(dbResponse.ConnectionProperties, connection)
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionUpdate, Kind: KindDatabasePassword, Name: spec.Microservice, Detail: reason})
+	annotations := map[string]string{database.PasswordRotatedAtAnnotation: now.Format(time.RFC3339)}
+	maps.Copy(annotations, credentials.ServedRotationRequest(c.rotationRequest))
+	return annotations, nil
+}
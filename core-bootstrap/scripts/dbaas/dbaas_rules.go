@@ -0,0 +1,178 @@
+package dbaas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/rules"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// https://perch.qubership.org/display/CLOUDCORE/How+to+configure+namespace+autobalance+rules
+func (c *Configurer) namespaceRulesURL() string {
+	return fmt.Sprintf("%s/api/v3/dbaas/%s/physical_databases/balancing/rules", c.ApiDbaasAddress, c.Namespace)
+}
+
+// https://perch.qubership.org/display/CLOUDCORE/On+Microservice+physical+DB+balancing+rule
+func (c *Configurer) onMicroserviceRulesURL() string {
+	return fmt.Sprintf("%s/api/v3/dbaas/%s/physical_databases/rules/onMicroservices", c.ApiDbaasAddress, c.Namespace)
+}
+
+// desiredNamespaceRules returns bodies of declared namespace rules by their names
+func (c *Configurer) desiredNamespaceRules() map[string]interface{} {
+	desired := make(map[string]interface{}, len(c.NamespaceRules))
+	for _, rule := range c.NamespaceRules {
+		desired[rule.Name] = rule.Body()
+	}
+	return desired
+}
+
+// isStaleRule tells if a namespace rule not declared anymore may be deleted: only rules named like the ones created
+// by this task are deleted and only if DBAAS_DELETE_STALE_BALANCING_RULES is true
+func (c *Configurer) isStaleRule(name string) bool {
+	return c.deleteStaleRules && c.isNamespaceRule(name)
+}
+
+// isNamespaceRule tells if the rule is named like the ones created by this task, `<namespace>-<type>'
+func (c *Configurer) isNamespaceRule(name string) bool {
+	return strings.HasPrefix(name, c.Namespace+"-")
+}
+
+// namespaceRuleChanges diffs declared namespace rules with the existing ones, nil means there is nothing to reconcile
+func (c *Configurer) namespaceRuleChanges(ctx context.Context) ([]rules.Change, error) {
+	desired := c.desiredNamespaceRules()
+	if len(desired) == 0 && !c.deleteStaleRules {
+		return nil, nil
+	}
+	documents, known, err := c.readRules(ctx, c.namespaceRulesURL())
+	if err != nil {
+		return nil, err
+	}
+	var current map[string]interface{}
+	if known {
+		current = rules.IndexByName(documents)
+	}
+	return rules.Diff(desired, current, c.isStaleRule), nil
+}
+
+// onMicroserviceRuleChanges diffs DBAAS_ON_MICROSERVICES_PHYSDB_RULE with the existing rules of the namespace,
+// all on-microservice rules of the namespace are one document named after the namespace
+func (c *Configurer) onMicroserviceRuleChanges(ctx context.Context) ([]rules.Change, error) {
+	if c.MicroserviceAutobalanceRules == "" {
+		return nil, nil
+	}
+	var desired interface{}
+	if err := json.Unmarshal([]byte(c.MicroserviceAutobalanceRules), &desired); err != nil {
+		return nil, fmt.Errorf("invalid DBAAS_ON_MICROSERVICES_PHYSDB_RULE: %w", err)
+	}
+	documents, known, err := c.readRules(ctx, c.onMicroserviceRulesURL())
+	if err != nil {
+		return nil, err
+	}
+	var current map[string]interface{}
+	if known {
+		current = make(map[string]interface{})
+		if len(documents) > 0 {
+			current[c.Namespace] = documents
+		}
+	}
+	return rules.Diff(map[string]interface{}{c.Namespace: desired}, current, nil), nil
+}
+
+// readRules reads rules of the collection, false is returned if DBaaS does not allow to read it
+func (c *Configurer) readRules(ctx context.Context, url string) ([]interface{}, bool, error) {
+	var documents []interface{}
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBasicAuth(c.Username, c.password).
+		SetResult(&documents).
+		Get(url)
+	if err != nil {
+		return nil, false, utils.LogError(logger, ctx, "Error reading dbaas balancing rules: %w", err)
+	}
+	switch resp.StatusCode() {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		logger.WarnC(ctx, "Cannot read dbaas balancing rules from %s [HTTP status: %d], declared rules are used instead", url, resp.StatusCode())
+		return nil, false, nil
+	}
+	if resp.IsError() {
+		return nil, false, utils.LogError(logger, ctx, "Error reading dbaas balancing rules [HTTP status: %d]: %s", resp.StatusCode(), resp.String())
+	}
+	return documents, true, nil
+}
+
+func (c *Configurer) applyNamespaceRules(ctx context.Context, changes []rules.Change) error {
+	logger.InfoC(ctx, "DBaaS per namespace balancing rules diff:\n%s", rules.Describe(changes))
+	for _, change := range changes {
+		if change.Action == taskmanager.ActionNone {
+			taskmanager.RecordChange(ctx, ruleChange(KindBalancingRule, change))
+			continue
+		}
+
+		request := c.httpClient.R().
+			SetContext(ctx).
+			SetBasicAuth(c.Username, c.password)
+		url := c.namespaceRulesURL() + "/" + change.Name
+		var err error
+		if change.Action == taskmanager.ActionDelete {
+			logger.InfoC(ctx, "Deleting stale dbaas balancing rule %s, url: %s", change.Name, url)
+			err = checkRuleResponse(request.Delete(url))
+		} else {
+			logger.InfoC(ctx, "Sending dbaas auto balancing rule %s, url: %s", change.Name, url)
+			err = checkRuleResponse(request.SetHeader("Content-Type", "application/json").SetBody(change.Desired).Put(url))
+		}
+		if err != nil {
+			return utils.LogError(logger, ctx, "Error during %s of DBaaS per namespace balancing rule %s: %w", change.Action, change.Name, err)
+		}
+		taskmanager.RecordChange(ctx, ruleChange(KindBalancingRule, change))
+	}
+	return nil
+}
+
+func (c *Configurer) applyOnMicroserviceRules(ctx context.Context, changes []rules.Change) error {
+	logger.InfoC(ctx, "DBaaS on microservice balancing rules diff:\n%s", rules.Describe(changes))
+	for _, change := range changes {
+		if change.Action != taskmanager.ActionNone {
+			url := c.onMicroserviceRulesURL()
+			logger.InfoC(ctx, "Sending dbaas auto balancing rule on ms to url: %s", url)
+			err := checkRuleResponse(c.httpClient.R().
+				SetContext(ctx).
+				SetBasicAuth(c.Username, c.password).
+				SetHeader("Content-Type", "application/json").
+				SetBody(c.MicroserviceAutobalanceRules).
+				Put(url))
+			if err != nil {
+				return utils.LogError(logger, ctx, "Error sending on ms balancing rule: %w", err)
+			}
+		}
+		taskmanager.RecordChange(ctx, ruleChange(KindOnMicroserviceRules, change))
+	}
+	return nil
+}
+
+// checkRuleResponse fails on error statuses, except for deletion of a rule which does not exist
+func checkRuleResponse(resp *resty.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusNotFound && resp.Request.Method == http.MethodDelete {
+		return nil
+	}
+	if resp.IsError() {
+		return fmt.Errorf("HTTP request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
+func ruleChange(kind string, change rules.Change) taskmanager.Change {
+	result := taskmanager.Change{Action: change.Action, Kind: kind, Name: change.Name}
+	if change.Desired != nil {
+		result.Detail = rules.Render(change.Desired)
+	}
+	return result
+}
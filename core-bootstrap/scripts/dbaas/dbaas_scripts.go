@@ -3,13 +3,28 @@ package dbaas
 import (
 	"context"
 	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	k8scredentials "github.com/netcracker/core-bootstrap/v2/credentials/kubernetes"
+	"github.com/netcracker/core-bootstrap/v2/httpclient"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/database"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas/rules"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
-	"strconv"
 	"strings"
 	"time"
 )
 
+const (
+	TaskName                = "dbaas"
+	KindBalancingRule       = "DbaasBalancingRule"
+	KindOnMicroserviceRules = "DbaasOnMicroserviceRules"
+	KindDatabase            = "DbaasDatabase"
+	KindDatabasePassword    = "DbaasDatabasePassword"
+)
+
 var logger = logging.GetLogger("dbaas")
 
 type Configurer struct {
@@ -17,232 +32,200 @@ type Configurer struct {
 	ApiDbaasAddress              string
 	Username                     string
 	password                     string
-	GlobalAutobalanceRules       []string
+	NamespaceRules               []rules.NamespaceRule
 	MicroserviceAutobalanceRules string
-}
-
-type DbConnectionProperties struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	Role     string `json:"role"`
-	Name     string `json:"name"`
-	Url      string `json:"url"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	TLS      string `json:"tls"`
-}
-
-type DatabaseResponse struct {
-	Name                 string                 `json:"name"`
-	ConnectionProperties DbConnectionProperties `json:"connectionProperties"`
+	deleteStaleRules             bool
+	// rotationRequest and passwordMaxAge control rotation of passwords of provisioned databases
+	rotationRequest string
+	passwordMaxAge  time.Duration
+	backoff         database.Backoff
+	httpClient      *resty.Client
+	sink            credentials.Sink
 }
 
 func New() *Configurer {
 	return &Configurer{}
 }
 
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
 func (c *Configurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
-	c.ApiDbaasAddress = utils.MustGetEnv(accessor, "API_DBAAS_ADDRESS")
-	c.Username = utils.MustGetEnv(accessor, "DBAAS_CLUSTER_DBA_CREDENTIALS_USERNAME")
-	c.password = utils.MustGetEnv(accessor, "DBAAS_CLUSTER_DBA_CREDENTIALS_PASSWORD")
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.ApiDbaasAddress = reader.Required("API_DBAAS_ADDRESS")
+	reader.Check("API_DBAAS_ADDRESS", configsource.ValidURL(c.ApiDbaasAddress))
+	c.httpClient = utils.NewEndpointClient(reader, httpclient.DBaaS, c.Namespace)
+	c.Username = reader.Required("DBAAS_CLUSTER_DBA_CREDENTIALS_USERNAME")
+	c.password = reader.Required("DBAAS_CLUSTER_DBA_CREDENTIALS_PASSWORD")
+
+	// structured rules take the whole rule schema, the legacy `dbType=>phydbid||...' format only perNamespace rules
+	structured := reader.Optional("DBAAS_NAMESPACE_BALANCING_RULES")
+	legacy := reader.Optional("DBAAS_LODB_PER_NAMESPACE_AUTOBALANCE_RULES")
+	var err error
+	switch {
+	case structured != "" && strings.TrimSpace(legacy) != "":
+		reader.Fail("only one of DBAAS_NAMESPACE_BALANCING_RULES and DBAAS_LODB_PER_NAMESPACE_AUTOBALANCE_RULES can be set")
+	case structured != "":
+		c.NamespaceRules, err = rules.ParseNamespaceRules(structured, c.Namespace)
+		reader.Check("DBAAS_NAMESPACE_BALANCING_RULES", err)
+	default:
+		c.NamespaceRules, err = rules.ParseLegacyNamespaceRules(legacy, c.Namespace)
+		reader.Check("DBAAS_LODB_PER_NAMESPACE_AUTOBALANCE_RULES", err)
+	}
 
-	raw := accessor("DBAAS_LODB_PER_NAMESPACE_AUTOBALANCE_RULES")
-	c.GlobalAutobalanceRules = strings.Split(strings.ReplaceAll(raw, " ", ""), "||")
+	c.deleteStaleRules = reader.Boolean("DBAAS_DELETE_STALE_BALANCING_RULES")
 
-	c.MicroserviceAutobalanceRules = accessor("DBAAS_ON_MICROSERVICES_PHYSDB_RULE")
-	return nil
+	c.MicroserviceAutobalanceRules = reader.Optional("DBAAS_ON_MICROSERVICES_PHYSDB_RULE")
+	reader.Check("DBAAS_ON_MICROSERVICES_PHYSDB_RULE", configsource.ValidJSON(c.MicroserviceAutobalanceRules))
+
+	c.rotationRequest = credentials.RotationRequest(reader, "DBAAS_PASSWORD_ROTATE")
+	c.passwordMaxAge = reader.Duration("DBAAS_PASSWORD_MAX_AGE")
+
+	c.backoff = database.DefaultBackoff
+	if interval := reader.Duration("DBAAS_POLL_INITIAL_INTERVAL"); interval > 0 {
+		c.backoff.InitialInterval = interval
+	}
+	if interval := reader.Duration("DBAAS_POLL_MAX_INTERVAL"); interval > 0 {
+		c.backoff.MaxInterval = interval
+	}
+	if timeout := reader.Duration("DBAAS_PROVISION_TIMEOUT"); timeout > 0 {
+		c.backoff.Timeout = timeout
+	}
+	if err := c.backoff.Validate(); err != nil {
+		reader.Fail("invalid DBaaS polling configuration: %w", err)
+	}
+	c.sink = k8scredentials.ReadSink(reader, c.Namespace)
+	return reader.Err()
 }
 
+// Sink returns the sink database credentials are stored in, available after Configure
+func (c *Configurer) Sink() credentials.Sink {
+	return c.sink
+}
+
+// Execute reconciles balancing rules: only rules differing from the existing ones are applied,
+// stale per-namespace rules are deleted if DBAAS_DELETE_STALE_BALANCING_RULES is true
 func (c *Configurer) Execute(ctx context.Context) error {
-	if len(c.GlobalAutobalanceRules) > 0 && c.GlobalAutobalanceRules[0] != "" {
-		if err := c.createDbaasAutoBalanceRulesOnNamespace(ctx); err != nil {
+	namespaceChanges, err := c.namespaceRuleChanges(ctx)
+	if err != nil {
+		return fmt.Errorf("error apply dbaas rules: %w", err)
+	}
+	if namespaceChanges != nil {
+		if err := c.applyNamespaceRules(ctx, namespaceChanges); err != nil {
 			return fmt.Errorf("error apply dbaas rules: %w", err)
 		}
 	} else {
 		logger.InfoC(ctx, "No DBAAS_LODB_PER_NAMESPACE_AUTOBALANCE_RULES found, skipping CreateDbaasAutoBalanceRulesOnNamespace")
 	}
 
-	if c.MicroserviceAutobalanceRules != "" {
-		err := c.createDbaasAutoBalanceRulesOnMs(ctx)
-		if err != nil {
-			return utils.LogError(logger, ctx, "error during CreateDbaasAutoBalanceRulesOnMs: %w", err)
-		}
-	} else {
+	msChanges, err := c.onMicroserviceRuleChanges(ctx)
+	if err != nil {
+		return utils.LogError(logger, ctx, "error during CreateDbaasAutoBalanceRulesOnMs: %w", err)
+	}
+	if msChanges == nil {
 		logger.InfoC(ctx, "No DBAAS_ON_MICROSERVICES_PHYSDB_RULE, skipping CreateDbaasAutoBalanceRulesOnMs")
 		return nil
 	}
-
+	if err := c.applyOnMicroserviceRules(ctx, msChanges); err != nil {
+		return utils.LogError(logger, ctx, "error during CreateDbaasAutoBalanceRulesOnMs: %w", err)
+	}
 	return nil
 }
 
-func (c *Configurer) CreateDatabase(ctx context.Context, microserviceName string, secretName string, namingMapper map[string]string) error {
-	dbProperties, err := c.getOrCreateDb(ctx, microserviceName)
+// Plan reports the diff of declared and existing balancing rules, all declared rules are reported as applied
+// if DBaaS does not allow to read existing ones
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	var changes []taskmanager.Change
+	namespaceChanges, err := c.namespaceRuleChanges(ctx)
 	if err != nil {
-		return fmt.Errorf("error get or create database for `%s': %w", microserviceName, err)
+		return nil, err
 	}
-
-	data := map[string][]byte{
-		mapSecretName("dbhostname", namingMapper): []byte(dbProperties.Host),
-		mapSecretName("dbport", namingMapper):     []byte(strconv.Itoa(dbProperties.Port)),
-		mapSecretName("role", namingMapper):       []byte(dbProperties.Role),
-		mapSecretName("dbname", namingMapper):     []byte(dbProperties.Name),
-		mapSecretName("url", namingMapper):        []byte(dbProperties.Url),
-		mapSecretName("username", namingMapper):   []byte(dbProperties.Username),
-		mapSecretName("password", namingMapper):   []byte(dbProperties.Password),
-		mapSecretName("tls", namingMapper):        []byte(dbProperties.TLS),
+	for _, change := range namespaceChanges {
+		changes = append(changes, ruleChange(KindBalancingRule, change))
 	}
-
-	return utils.CreateSecretWithDbCredsData(ctx, c.Namespace, secretName, data)
-}
-
-func mapSecretName(name string, namingMapper map[string]string) string {
-	if namingMapper == nil {
-		return name
+	msChanges, err := c.onMicroserviceRuleChanges(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	newName, ok := namingMapper[name]
-	if !ok {
-		return name
-	} else {
-		return newName
+	for _, change := range msChanges {
+		changes = append(changes, ruleChange(KindOnMicroserviceRules, change))
 	}
+	return changes, nil
 }
 
-func (c *Configurer) getOrCreateDb(ctx context.Context, microserviceName string) (DbConnectionProperties, error) {
-	dbaasCreateDbURL := fmt.Sprintf("%s/api/v3/dbaas/%s/databases", c.ApiDbaasAddress, c.Namespace)
-	logger.InfoC(ctx, fmt.Sprintf("Registering %s database in DbaaS, URL: %s", microserviceName, dbaasCreateDbURL))
-
-	classifier := map[string]string{
-		"namespace":        c.Namespace,
-		"microserviceName": microserviceName,
-		"scope":            "service",
-	}
-	databaseToRegister := map[string]interface{}{
-		"classifier":    classifier,
-		"originService": microserviceName,
-		"type":          "postgresql",
-	}
-
-	var dbResponse DatabaseResponse
-	maxAttempts := 10
-	for attempts := 0; attempts < maxAttempts; attempts++ {
-		resp, err := utils.RestyClient.R().
-			SetContext(ctx).
-			SetBasicAuth(c.Username, c.password).
-			SetHeader("Content-Type", "application/json").
-			SetBody(databaseToRegister).
-			SetResult(&dbResponse).
-			Put(dbaasCreateDbURL)
-
-		if err != nil {
-			return DbConnectionProperties{}, utils.LogError(logger, ctx, "Error sending request to dbaas: %v", err)
-		}
-
-		if resp.StatusCode() == 202 {
-			logger.InfoC(ctx, "Got 202 ACCEPTED response, retrying in 3 seconds...")
-			time.Sleep(3 * time.Second)
-			continue
-		}
-
-		if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
-			return DbConnectionProperties{}, utils.LogError(logger, ctx, "Wrong dbaas response status: %s, resp: %s", resp.Status(), resp.String())
-		}
-
-		if resp.StatusCode() == 200 {
-			logger.InfoC(ctx, "Database already exists, skipping creation")
-		}
+// CreateDatabase provisions postgresql database of the microservice and stores its credentials in the secret
+func (c *Configurer) CreateDatabase(ctx context.Context, undoLog *utils.UndoLog, microserviceName string, secretName string, namingMapper map[string]string) error {
+	return c.ProvisionDatabase(ctx, undoLog, database.Spec{
+		Microservice: microserviceName,
+		Type:         database.PostgreSQL,
+		SecretName:   secretName,
+		NamingMapper: namingMapper,
+	})
+}
 
-		logger.InfoC(ctx, fmt.Sprintf("Database creation successful: %+v", dbResponse))
-		break
+// ProvisionDatabase gets or creates the database of the spec and stores its credentials in the spec's secret
+// using the secret layout of the database type. The password is rotated first if rotation is due, see rotatePasswordIfDue.
+// Restoring of the overwritten secret is added to the undo log of the calling task, registered databases are kept.
+func (c *Configurer) ProvisionDatabase(ctx context.Context, undoLog *utils.UndoLog, spec database.Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
 	}
-
-	if dbResponse.ConnectionProperties.Host == "" || dbResponse.ConnectionProperties.Username == "" || dbResponse.ConnectionProperties.Password == "" {
-		return DbConnectionProperties{}, utils.LogError(logger, ctx, "some database connection properties are missing, cannot prepare database: %v", dbResponse)
+	dbResponse, err := c.getOrCreateDb(ctx, spec)
+	if err != nil {
+		return fmt.Errorf("error get or create %s database for `%s': %w", spec.DatabaseType(), spec.Microservice, err)
 	}
 
-	return dbResponse.ConnectionProperties, nil
-}
-
-// https://perch.qubership.org/display/CLOUDCORE/How+to+configure+namespace+autobalance+rules
-func (c *Configurer) createDbaasAutoBalanceRulesOnNamespace(ctx context.Context) error {
-	logger.InfoC(ctx, "*** starting CreateDbaasAutoBalanceRulesOnNamespace")
-
-	for _, entry := range c.GlobalAutobalanceRules {
-
-		ruleParts := strings.Split(entry, "=>")
-		if len(ruleParts) != 2 {
-			return utils.LogError(logger, ctx, "Invalid rule format: %s, skipping", entry)
-		}
-
-		dbType := ruleParts[0]
-		phyDbID := ruleParts[1]
-
-		ruleName := fmt.Sprintf("%s-%s", c.Namespace, dbType)
-		ruleJSON := fmt.Sprintf(`{
-			"type": "%s",
-			"rule": {
-				"config": {
-					"perNamespace": {
-						"phydbid": "%s"
-					}
-				},
-				"type": "perNamespace"
-			}
-		}`, dbType, phyDbID)
-
-		aggregatorRulesURL := fmt.Sprintf("%s/api/v3/dbaas/%s/physical_databases/balancing/rules/%s", c.ApiDbaasAddress, c.Namespace, ruleName)
-
-		logger.InfoC(ctx, "Sending dbaas auto balancing rule %s: %s, url: %s", ruleName, ruleJSON, aggregatorRulesURL)
-		resp, err := utils.RestyClient.R().
-			SetContext(ctx).
-			SetBasicAuth(c.Username, c.password).
-			SetHeader("Content-Type", "application/json").
-			SetBody(ruleJSON).
-			Put(aggregatorRulesURL)
-
-		if err != nil {
-			logger.ErrorC(ctx, "Error creating DBaaS per namespace balancing rule: %v", err)
-			return err
-		}
-
-		if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
-			logger.InfoC(ctx, "Error creating DBaaS per namespace balancing rule [HTTP status: %d]", resp.StatusCode())
-			logger.InfoC(ctx, "[HTTP body: %s]", resp.String())
-			return fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode())
-		}
-
-		logger.InfoC(ctx, "DBaaS auto balancing rule '%s' created successfully", ruleName)
+	existing, err := c.sink.Read(ctx, spec.SecretName)
+	if err != nil {
+		return fmt.Errorf("error reading %s `%s' before update: %w", c.sink.Kind(), spec.SecretName, err)
+	}
+	annotations, err := c.rotatePasswordIfDue(ctx, spec, existing, &dbResponse)
+	if err != nil {
+		return err
+	}
+	rotated := annotations != nil
+	// fresh credentials serve the rotation request, so they are not rotated by the next run
+	if existing == nil {
+		annotations = credentials.ServedRotationRequest(c.rotationRequest)
+	}
+	data, err := spec.SecretData(dbResponse)
+	if err != nil {
+		return utils.LogError(logger, ctx, "cannot prepare database of `%s': %w", spec.Microservice, err)
 	}
 
-	logger.InfoC(ctx, "*** finishing CreateDbaasAutoBalanceRulesOnNamespace")
+	if err := credentials.SaveOver(ctx, c.sink, existing, spec.SecretName, data, annotations); err != nil {
+		return utils.LogError(logger, ctx, "Error saving db credentials %s: %w", spec.SecretName, err)
+	}
+	// previous credentials are not valid after rotation, restoring them would break the consumers
+	if !rotated {
+		undoLog.Add(fmt.Sprintf("restore secret %s", spec.SecretName), credentials.Restore(c.sink, spec.SecretName, existing))
+	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionApply, Kind: c.sink.Kind(), Name: spec.SecretName})
 	return nil
 }
 
-// https://perch.qubership.org/display/CLOUDCORE/On+Microservice+physical+DB+balancing+rule
-func (c *Configurer) createDbaasAutoBalanceRulesOnMs(ctx context.Context) error {
-	logger.InfoC(ctx, "*** starting CreateDbaasAutoBalanceRulesOnMs")
-
-	aggregatorRulesURL := fmt.Sprintf("%s/api/v3/dbaas/%s/physical_databases/rules/onMicroservices", c.ApiDbaasAddress, c.Namespace)
-	logger.InfoC(ctx, "Sending dbaas auto balancing rule on ms to url: %s", aggregatorRulesURL)
+// getOrCreateDb requests the database and records whether it was created, see database.Provisioner
+func (c *Configurer) getOrCreateDb(ctx context.Context, spec database.Spec) (database.Response, error) {
+	dbaasCreateDbURL := fmt.Sprintf("%s/api/v3/dbaas/%s/databases", c.ApiDbaasAddress, c.Namespace)
+	logger.InfoC(ctx, "Registering %s %s database in DbaaS, URL: %s", spec.Microservice, spec.DatabaseType(), dbaasCreateDbURL)
 
-	resp, err := utils.RestyClient.R().
-		SetContext(ctx).
-		SetBasicAuth(c.Username, c.password).
-		SetHeader("Content-Type", "application/json").
-		SetBody(c.MicroserviceAutobalanceRules).
-		Put(aggregatorRulesURL)
+	databaseToRegister := spec.Request(c.Namespace)
 
+	operation := fmt.Sprintf("provisioning of %s database of `%s'", spec.DatabaseType(), spec.Microservice)
+	provisioner := database.Provisioner{Client: c.httpClient, Username: c.Username, Password: c.password, Backoff: c.backoff}
+	dbResponse, created, err := provisioner.GetOrCreate(ctx, dbaasCreateDbURL, databaseToRegister, operation)
 	if err != nil {
-		return utils.LogError(logger, ctx, "Error sending on ms balancing rule: %v", err)
+		return database.Response{}, utils.LogError(logger, ctx, "Error during %s: %w", operation, err)
 	}
 
-	if resp.StatusCode() != 200 && resp.StatusCode() != 201 {
-		logger.InfoC(ctx, "Error creating dbaas on ms balancing rule [HTTP status: %v]", resp.StatusCode())
-		logger.InfoC(ctx, "[HTTP body: %s]", resp.String())
-		return fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode())
+	change := taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindDatabase, Name: spec.Microservice, Detail: string(spec.DatabaseType())}
+	if !created {
+		logger.InfoC(ctx, "Database already exists, skipping creation")
+		change.Action = taskmanager.ActionNone
 	}
-
-	logger.InfoC(ctx, "DBaaS auto balancing rule created successfully")
-	return nil
+	taskmanager.RecordChange(ctx, change)
+	logger.InfoC(ctx, "Database %s of `%s' is ready", dbResponse.Name, spec.Microservice)
+	return dbResponse, nil
 }
@@ -0,0 +1,151 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+)
+
+// Change is a difference between desired and current rule, Desired is nil for deleted rules and Current is nil
+// for created ones or if current rules are unknown
+type Change struct {
+	Action  taskmanager.Action
+	Name    string
+	Desired interface{}
+	Current interface{}
+}
+
+// Diff compares desired rules with current ones by name. A rule is unchanged if all fields of the desired document
+// have the same values in the current one, fields added by DBaaS are ignored. Current rules not desired are deleted
+// if stale returns true for their names. If current is nil, current rules are unknown and all desired are applied.
+func Diff(desired, current map[string]interface{}, stale func(name string) bool) []Change {
+	var changes []Change
+	for _, name := range sortedNames(desired) {
+		change := Change{Name: name, Desired: desired[name]}
+		existing, ok := current[name]
+		switch {
+		case current == nil:
+			change.Action = taskmanager.ActionApply
+		case !ok:
+			change.Action = taskmanager.ActionCreate
+		case Matches(desired[name], existing):
+			change.Action = taskmanager.ActionNone
+			change.Current = existing
+		default:
+			change.Action = taskmanager.ActionUpdate
+			change.Current = existing
+		}
+		changes = append(changes, change)
+	}
+	for _, name := range sortedNames(current) {
+		if _, ok := desired[name]; !ok && stale != nil && stale(name) {
+			changes = append(changes, Change{Action: taskmanager.ActionDelete, Name: name, Current: current[name]})
+		}
+	}
+	return changes
+}
+
+// Matches checks that every field of desired document has the same value in current one, arrays must have equal length.
+// Documents are compared in their JSON form, so structs and decoded JSON can be mixed.
+func Matches(desired, current interface{}) bool {
+	return matches(normalize(desired), normalize(current))
+}
+
+func matches(desired, current interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range d {
+			if !matches(value, c[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !matches(d[i], c[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, current)
+	}
+}
+
+func normalize(document interface{}) interface{} {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return document
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return document
+	}
+	return normalized
+}
+
+// IndexByName maps rule documents returned by DBaaS by their `name' field, documents without name are skipped
+func IndexByName(documents []interface{}) map[string]interface{} {
+	index := make(map[string]interface{}, len(documents))
+	for _, document := range documents {
+		fields, _ := document.(map[string]interface{})
+		if name, ok := fields["name"].(string); ok && name != "" {
+			index[name] = document
+		}
+	}
+	return index
+}
+
+// Describe renders changes for the log, unchanged rules are listed by name only
+func Describe(changes []Change) string {
+	var lines, unchanged []string
+	for _, change := range changes {
+		switch change.Action {
+		case taskmanager.ActionNone:
+			unchanged = append(unchanged, change.Name)
+		case taskmanager.ActionUpdate:
+			lines = append(lines, fmt.Sprintf("update %s: %s -> %s", change.Name, Render(change.Current), Render(change.Desired)))
+		case taskmanager.ActionDelete:
+			lines = append(lines, fmt.Sprintf("delete %s: %s", change.Name, Render(change.Current)))
+		default:
+			lines = append(lines, fmt.Sprintf("%s %s: %s", change.Action, change.Name, Render(change.Desired)))
+		}
+	}
+	if len(unchanged) > 0 {
+		lines = append(lines, "unchanged: "+strings.Join(unchanged, ", "))
+	}
+	if len(lines) == 0 {
+		return "no rules"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Render returns the document as compact JSON
+func Render(document interface{}) string {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Sprint(document)
+	}
+	return string(data)
+}
+
+func sortedNames(documents map[string]interface{}) []string {
+	names := make([]string, 0, len(documents))
+	for name := range documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, document string) interface{} {
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+	return decoded
+}
+
+func perNamespace(dbType, phydbid string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": dbType,
+		"rule": map[string]interface{}{
+			"type":   "perNamespace",
+			"config": map[string]interface{}{"perNamespace": map[string]interface{}{"phydbid": phydbid}},
+		},
+	}
+}
+
+func TestMatches_IgnoresFieldsAddedByDbaas(t *testing.T) {
+	current := decode(t, `{"name": "core-postgresql", "namespace": "core", "order": 0, "type": "postgresql",
+		"rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "pg-1"}}}}`)
+
+	assert.True(t, Matches(perNamespace("postgresql", "pg-1"), current))
+	assert.False(t, Matches(perNamespace("postgresql", "pg-2"), current))
+	assert.False(t, Matches([]interface{}{"a"}, decode(t, `["a", "b"]`)))
+	assert.True(t, Matches(decode(t, `[{"type": "postgresql"}]`), decode(t, `[{"type": "postgresql", "id": 1}]`)))
+}
+
+func TestDiff(t *testing.T) {
+	desired := map[string]interface{}{
+		"core-postgresql": perNamespace("postgresql", "pg-1"),
+		"core-mongodb":    perNamespace("mongodb", "mongo-2"),
+		"core-cassandra":  perNamespace("cassandra", "cassandra-1"),
+	}
+	current := map[string]interface{}{
+		"core-postgresql": decode(t, `{"name": "core-postgresql", "type": "postgresql", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "pg-1"}}}}`),
+		"core-mongodb":    decode(t, `{"name": "core-mongodb", "type": "mongodb", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "mongo-1"}}}}`),
+		"core-opensearch": decode(t, `{"name": "core-opensearch", "type": "opensearch"}`),
+		"manual":          decode(t, `{"name": "manual", "type": "opensearch"}`),
+	}
+
+	changes := Diff(desired, current, func(name string) bool { return name != "manual" })
+
+	actions := map[string]taskmanager.Action{}
+	for _, change := range changes {
+		actions[change.Name] = change.Action
+	}
+	assert.Equal(t, map[string]taskmanager.Action{
+		"core-cassandra":  taskmanager.ActionCreate,
+		"core-mongodb":    taskmanager.ActionUpdate,
+		"core-postgresql": taskmanager.ActionNone,
+		"core-opensearch": taskmanager.ActionDelete,
+	}, actions)
+	assert.Equal(t, "core-opensearch", changes[len(changes)-1].Name)
+}
+
+func TestDiff_UnknownCurrent(t *testing.T) {
+	changes := Diff(map[string]interface{}{"core-postgresql": perNamespace("postgresql", "pg-1")}, nil, func(string) bool { return true })
+
+	require.Len(t, changes, 1)
+	assert.Equal(t, taskmanager.ActionApply, changes[0].Action)
+}
+
+func TestDiff_KeepsStaleRulesByDefault(t *testing.T) {
+	changes := Diff(map[string]interface{}{}, map[string]interface{}{"core-postgresql": perNamespace("postgresql", "pg-1")}, nil)
+	assert.Empty(t, changes)
+}
+
+func TestIndexByName(t *testing.T) {
+	index := IndexByName(decode(t, `[{"name": "core-postgresql"}, {"type": "mongodb"}, "broken"]`).([]interface{}))
+	assert.Equal(t, []string{"core-postgresql"}, sortedNames(index))
+}
+
+func TestDescribe(t *testing.T) {
+	description := Describe([]Change{
+		{Action: taskmanager.ActionNone, Name: "core-postgresql"},
+		{Action: taskmanager.ActionCreate, Name: "core-mongodb", Desired: map[string]string{"type": "mongodb"}},
+		{Action: taskmanager.ActionUpdate, Name: "core-opensearch", Current: map[string]string{"type": "a"}, Desired: map[string]string{"type": "b"}},
+		{Action: taskmanager.ActionDelete, Name: "core-cassandra", Current: map[string]string{"type": "cassandra"}},
+	})
+	assert.Equal(t, `create core-mongodb: {"type":"mongodb"}
+update core-opensearch: {"type":"a"} -> {"type":"b"}
+delete core-cassandra: {"type":"cassandra"}
+unchanged: core-postgresql`, description)
+	assert.Equal(t, "no rules", Describe(nil))
+}
@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const PerNamespaceRuleType = "perNamespace"
+
+// NamespaceRule is a per-namespace physical database balancing rule. Name addresses the rule in DBaaS and is not
+// a part of its body, it defaults to `<namespace>-<type>'
+type NamespaceRule struct {
+	Name   string            `json:"name,omitempty"`
+	Order  int               `json:"order,omitempty"`
+	Type   string            `json:"type"`
+	Rule   Rule              `json:"rule"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Rule selects the physical database, Config holds the configuration of the rule type under the type name, e.g.
+// `perNamespace: {phydbid: postgres-dev}' for perNamespace rules
+type Rule struct {
+	Type   string                            `json:"type"`
+	Config map[string]map[string]interface{} `json:"config"`
+}
+
+// PerNamespaceConfig is the configuration of perNamespace rules
+type PerNamespaceConfig struct {
+	PhyDbID string `json:"phydbid"`
+}
+
+// ruleBody is the document sent to DBaaS
+type ruleBody struct {
+	Order  int               `json:"order,omitempty"`
+	Type   string            `json:"type"`
+	Rule   Rule              `json:"rule"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewPerNamespaceRule returns the rule assigning databases of the type in the namespace to the physical database
+func NewPerNamespaceRule(namespace, dbType, phyDbID string) NamespaceRule {
+	return NamespaceRule{
+		Name: RuleName(namespace, dbType),
+		Type: dbType,
+		Rule: Rule{
+			Type:   PerNamespaceRuleType,
+			Config: map[string]map[string]interface{}{PerNamespaceRuleType: {"phydbid": phyDbID}},
+		},
+	}
+}
+
+// RuleName is the default name of the namespace rule of the database type
+func RuleName(namespace, dbType string) string {
+	return fmt.Sprintf("%s-%s", namespace, dbType)
+}
+
+// Body returns the rule document as accepted by DBaaS
+func (r NamespaceRule) Body() interface{} {
+	return ruleBody{Order: r.Order, Type: r.Type, Rule: r.Rule, Labels: r.Labels}
+}
+
+// Validate checks the rule has a database type, a rule type and the configuration of the rule type
+func (r NamespaceRule) Validate() error {
+	if r.Type == "" {
+		return fmt.Errorf("rule '%s': database type is empty", r.Name)
+	}
+	if r.Rule.Type == "" {
+		return fmt.Errorf("rule '%s': rule type is empty", r.Name)
+	}
+	config, ok := r.Rule.Config[r.Rule.Type]
+	if !ok {
+		return fmt.Errorf("rule '%s': configuration of %s rule type is missing", r.Name, r.Rule.Type)
+	}
+	if r.Rule.Type == PerNamespaceRuleType {
+		var perNamespace PerNamespaceConfig
+		if err := convert(config, &perNamespace); err != nil {
+			return fmt.Errorf("rule '%s': invalid %s configuration: %w", r.Name, PerNamespaceRuleType, err)
+		}
+		if perNamespace.PhyDbID == "" {
+			return fmt.Errorf("rule '%s': phydbid of %s configuration is empty", r.Name, PerNamespaceRuleType)
+		}
+	}
+	return nil
+}
+
+// ParseNamespaceRules reads a YAML or JSON list of namespace rules, rules without name get the default one
+//
+//	[{"type": "postgresql", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "postgres-dev"}}}}]
+func ParseNamespaceRules(data, namespace string) ([]NamespaceRule, error) {
+	var rules []NamespaceRule
+	if err := yaml.UnmarshalStrict([]byte(data), &rules); err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].Name == "" && rules[i].Type != "" {
+			rules[i].Name = RuleName(namespace, rules[i].Type)
+		}
+		if err := rules[i].Validate(); err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+	}
+	return rules, checkUniqueNames(rules)
+}
+
+// ParseLegacyNamespaceRules reads `dbType=>phydbid||dbType=>phydbid' format of perNamespace rules, spaces are ignored.
+// As before, a later rule of the same database type replaces the earlier one.
+func ParseLegacyNamespaceRules(raw, namespace string) ([]NamespaceRule, error) {
+	raw = strings.ReplaceAll(raw, " ", "")
+	if raw == "" {
+		return nil, nil
+	}
+	var rules []NamespaceRule
+	for _, entry := range strings.Split(raw, "||") {
+		ruleParts := strings.Split(entry, "=>")
+		if len(ruleParts) != 2 || ruleParts[0] == "" || ruleParts[1] == "" {
+			return nil, fmt.Errorf("invalid rule format: %s", entry)
+		}
+		rules = append(rules, NewPerNamespaceRule(namespace, ruleParts[0], ruleParts[1]))
+	}
+	return rules, nil
+}
+
+func checkUniqueNames(rules []NamespaceRule) error {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if names[rule.Name] {
+			return fmt.Errorf("rule '%s' is declared twice", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+func convert(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(to)
+}
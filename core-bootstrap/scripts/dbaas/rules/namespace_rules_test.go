@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyNamespaceRules(t *testing.T) {
+	rules, err := ParseLegacyNamespaceRules("postgresql=>postgres-dev || mongodb=>mongo-dev", "core")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "core-postgresql", rules[0].Name)
+	assert.Equal(t, "core-mongodb", rules[1].Name)
+
+	body, err := json.Marshal(rules[0].Body())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "postgresql", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "postgres-dev"}}}}`, string(body))
+
+	rules, err = ParseLegacyNamespaceRules("", "core")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	_, err = ParseLegacyNamespaceRules("postgresql=>postgres-dev||mongodb", "core")
+	assert.EqualError(t, err, "invalid rule format: mongodb")
+}
+
+func TestParseNamespaceRules(t *testing.T) {
+	rules, err := ParseNamespaceRules(`
+- type: postgresql
+  order: 1
+  labels:
+    clusterName: "pg \"main\" => || cluster"
+  rule:
+    type: perNamespace
+    config:
+      perNamespace:
+        phydbid: postgres-dev
+- name: core-opensearch-by-label
+  type: opensearch
+  rule:
+    type: perLabel
+    config:
+      perLabel:
+        label: tier=gold
+`, "core")
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "core-postgresql", rules[0].Name)
+	assert.Equal(t, "core-opensearch-by-label", rules[1].Name)
+
+	body, err := json.Marshal(rules[0].Body())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"order": 1, "type": "postgresql", "labels": {"clusterName": "pg \"main\" => || cluster"},
+		"rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "postgres-dev"}}}}`, string(body))
+}
+
+func TestParseNamespaceRules_Invalid(t *testing.T) {
+	for document, expected := range map[string]string{
+		`[{"type": "postgresql", "rule": {"type": "perNamespace", "config": {}}}]`:                                                 "rule #1: rule 'core-postgresql': configuration of perNamespace rule type is missing",
+		`[{"type": "postgresql", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": ""}}}}]`:                  "rule #1: rule 'core-postgresql': phydbid of perNamespace configuration is empty",
+		`[{"type": "postgresql", "rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "pg", "extra": 1}}}}]`:    "rule #1: rule 'core-postgresql': invalid perNamespace configuration",
+		`[{"rule": {"type": "perNamespace", "config": {"perNamespace": {"phydbid": "pg"}}}}]`:                                      "rule #1: rule '': database type is empty",
+		`[{"type": "postgresql", "rule": {"config": {}}}]`:                                                                         "rule #1: rule 'core-postgresql': rule type is empty",
+		`[{"type": "postgresql", "rules": []}]`:                                                                                    "unknown field",
+		`[{"type": "pg", "rule": {"type": "t", "config": {"t": {}}}}, {"type": "pg", "rule": {"type": "t", "config": {"t": {}}}}]`: "rule 'core-pg' is declared twice",
+	} {
+		_, err := ParseNamespaceRules(document, "core")
+		assert.ErrorContains(t, err, expected, document)
+	}
+}
@@ -0,0 +1,55 @@
+package maas
+
+import (
+	"context"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+)
+
+// Cleanup is the uninstall task removing MaaS agent client registration and its credentials secret
+type Cleanup struct {
+	Configurer
+}
+
+func NewCleanup() *Cleanup {
+	return &Cleanup{}
+}
+
+func (c *Cleanup) Execute(ctx context.Context) error {
+	logger.InfoC(ctx, "*** Starting maas cleanup")
+	changes, err := c.Plan(ctx)
+	if err != nil {
+		return utils.LogError(logger, ctx, "Error reading maas agent secret: %w", err)
+	}
+	for _, change := range changes {
+		switch change.Kind {
+		case KindClient:
+			logger.InfoC(ctx, "Deleting MaaS client %s", change.Name)
+			err = c.deleteMaasClient(ctx, change.Name)
+		default:
+			logger.InfoC(ctx, "Deleting secret %s", change.Name)
+			err = c.sink.Delete(ctx, change.Name)
+		}
+		if err != nil {
+			return utils.LogError(logger, ctx, "Error deleting %s %s: %w", change.Kind, change.Name, err)
+		}
+		taskmanager.RecordChange(ctx, change)
+	}
+	logger.InfoC(ctx, "### Finished maas cleanup")
+	return nil
+}
+
+// Plan reports the registered client, unless it is the stub one, and the secret to be deleted
+func (c *Cleanup) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	secret, err := c.sink.Read(ctx, agentSecret)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	var changes []taskmanager.Change
+	if username := string(secret.Data["username"]); c.Enabled && username != "" && username != stubUsername {
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindClient, Name: username})
+	}
+	return append(changes, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: c.sink.Kind(), Name: agentSecret}), nil
+}
@@ -4,13 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/credentials"
+	k8scredentials "github.com/netcracker/core-bootstrap/v2/credentials/kubernetes"
+	"github.com/netcracker/core-bootstrap/v2/httpclient"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"strings"
 )
 
+const (
+	TaskName     = "maas"
+	KindConfig   = "MaasConfig"
+	KindClient   = "MaasClient"
+	agentSecret  = "cluster-maas-agent-credentials-secret"
+	stubUsername = "stub-client-not-registered-in-maas"
+)
+
 var logger = logging.GetLogger("maas")
 
 type Configurer struct {
@@ -20,23 +32,39 @@ type Configurer struct {
 	Config    string
 	Username  string
 	password  string
+	sink      credentials.Sink
+	// httpClient calls MaaS with the client profile and TLS settings of `maas' endpoint
+	httpClient *resty.Client
+	// passwordPolicy is the policy of generated agent client passwords
+	passwordPolicy credentials.PasswordPolicy
+	undoLog        utils.UndoLog
 }
 
 func New() *Configurer {
 	return &Configurer{}
 }
 
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
 func (c *Configurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
-	c.Enabled = utils.GetEnvBoolean(accessor, "MAAS_ENABLED")
-	c.Address = accessor("MAAS_INTERNAL_ADDRESS")
-	c.Username = accessor("MAAS_CREDENTIALS_USERNAME")
-	c.password = accessor("MAAS_CREDENTIALS_PASSWORD")
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	c.Enabled = reader.Boolean("MAAS_ENABLED")
+	c.Address = reader.Optional("MAAS_INTERNAL_ADDRESS")
+	reader.Check("MAAS_INTERNAL_ADDRESS", configsource.ValidURL(c.Address))
+	c.httpClient = utils.NewEndpointClient(reader, httpclient.MaaS, c.Namespace)
+	c.Username = reader.Optional("MAAS_CREDENTIALS_USERNAME")
+	c.password = reader.Optional("MAAS_CREDENTIALS_PASSWORD")
 	if c.Enabled && c.Address == "" {
-		return fmt.Errorf("MAAS_ENABLED set to true, but maas address is not specified via MAAS_INTERNAL_ADDRESS")
+		reader.Fail("MAAS_ENABLED set to true, but maas address is not specified via MAAS_INTERNAL_ADDRESS")
 	}
-	c.Config = accessor("MAAS_CONFIG")
-	return nil
+	c.Config = reader.Optional("MAAS_CONFIG")
+	reader.Check("MAAS_CONFIG", configsource.ValidYAML(c.Config))
+	c.passwordPolicy = credentials.ReadPasswordPolicy(reader, credentials.TargetMaaS)
+	c.sink = k8scredentials.ReadSink(reader, c.Namespace)
+	return reader.Err()
 }
 
 func (c *Configurer) Execute(ctx context.Context) error {
@@ -49,6 +77,43 @@ func (c *Configurer) Execute(ctx context.Context) error {
 	return nil
 }
 
+// Rollback deletes MaaS client registered during execution and restores previous agent credentials secret
+func (c *Configurer) Rollback(ctx context.Context) error {
+	return c.undoLog.Rollback(ctx)
+}
+
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	var changes []taskmanager.Change
+	if c.Config != "" {
+		changes = append(changes, taskmanager.Change{Action: taskmanager.ActionApply, Kind: KindConfig, Name: c.Namespace, Detail: c.Config})
+	}
+
+	secret, err := c.sink.Read(ctx, agentSecret)
+	if err != nil {
+		return nil, err
+	}
+	secretChange := taskmanager.Change{Action: taskmanager.CreateOrUpdate(secret != nil), Kind: c.sink.Kind(), Name: agentSecret, Detail: credentials.DriftDetail(secret)}
+	if !c.Enabled {
+		return append(changes, secretChange), nil
+	}
+
+	existingUsername, err := usernameFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if existingUsername != "" && existingUsername != stubUsername {
+		changes = append(changes, credentials.Drift(c.sink, agentSecret, secret)...)
+		return append(changes, taskmanager.Change{Action: taskmanager.ActionNone, Kind: KindClient, Name: existingUsername}), nil
+	}
+
+	newUsername := fmt.Sprintf("maas-agent-%s", c.Namespace)
+	return append(changes,
+		taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindClient, Name: newUsername, Detail: "previous registration, if any"},
+		taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindClient, Name: newUsername},
+		secretChange,
+	), nil
+}
+
 func (c *Configurer) sendMaaSConfig(ctx context.Context) error {
 	logger.InfoC(ctx, "*** starting SendMaaSConfig...")
 
@@ -73,7 +138,7 @@ spec:
 
 	logger.InfoC(ctx, "aggregated config: %s", configYaml)
 
-	resp, err := utils.RestyClient.R().
+	resp, err := c.httpClient.R().
 		SetContext(ctx).
 		SetBasicAuth(c.Username, c.password).
 		SetHeader("Content-Type", "application/json").
@@ -90,6 +155,7 @@ spec:
 		return fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode())
 	}
 
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionApply, Kind: KindConfig, Name: c.Namespace})
 	logger.InfoC(ctx, "MaaS config sent successfully")
 	return nil
 }
@@ -99,26 +165,34 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 
 	if !c.Enabled {
 		logger.InfoC(ctx, "MAAS_ENABLED is not true, creating secret with default credentials.")
-		err := c.createMaasAgentSecret(ctx, c.Namespace, "stub-client-not-registered-in-maas", "password")
+		err := c.createMaasAgentSecret(ctx, stubUsername, "password")
 		if err != nil {
 			return utils.LogError(logger, ctx, "Error creating stub secret: %w", err)
 		}
 		return nil
 	}
 
-	existingUsername, err := c.getExistingUsernameFromSecret(ctx, "cluster-maas-agent-credentials-secret")
+	secret, err := c.sink.Read(ctx, agentSecret)
+	if err != nil {
+		return utils.LogError(logger, ctx, "Error reading secret %s: %w", agentSecret, err)
+	}
+	existingUsername, err := usernameFromSecret(secret)
 	if err != nil {
 		return utils.LogError(logger, ctx, "Error getting existing username from secret: %w", err)
 	}
 
-	if existingUsername != "" && existingUsername != "stub-client-not-registered-in-maas" {
+	if existingUsername != "" && existingUsername != stubUsername {
 		logger.InfoC(ctx, "secret already exists, skipping MaasAgentCreateClient, existingUsername: %s", existingUsername)
+		credentials.ReportDrift(ctx, c.sink, agentSecret, secret)
 		return nil
 	}
 
 	logger.InfoC(ctx, "Secret not found or default credentials detected, creating a new client.")
 
-	newPassword := utils.GeneratePassword(10)
+	newPassword, err := c.passwordPolicy.Generate()
+	if err != nil {
+		return utils.LogError(logger, ctx, "Error generating maas agent password: %w", err)
+	}
 	newUsername := fmt.Sprintf("maas-agent-%s", c.Namespace)
 
 	logger.InfoC(ctx, "Creating new MaaS client: username=%s, maas_address=%s", newUsername, c.Address)
@@ -130,7 +204,7 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 		"username": newUsername,
 	}
 
-	deleteResp, err := utils.RestyClient.R().
+	deleteResp, err := c.httpClient.R().
 		SetContext(ctx).
 		SetBasicAuth(c.Username, c.password).
 		SetHeader("Content-Type", "application/json").
@@ -140,6 +214,9 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 		return utils.LogError(logger, ctx, "Error sending maas agent delete request: %w", err)
 	}
 	logger.InfoC(ctx, "Received the status from maas: %d", deleteResp.StatusCode())
+	if deleteResp.IsSuccess() {
+		taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionDelete, Kind: KindClient, Name: newUsername})
+	}
 
 	logger.InfoC(ctx, "Sending request to maas '%s' to create maas agent registration", clientUrl)
 	postPayload := map[string]interface{}{
@@ -149,7 +226,7 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 		"roles":     []string{"agent"},
 	}
 
-	postResp, err := utils.RestyClient.R().
+	postResp, err := c.httpClient.R().
 		SetContext(ctx).
 		SetBasicAuth(c.Username, c.password).
 		SetHeader("Content-Type", "application/json").
@@ -164,8 +241,12 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 	if postResp.StatusCode() != 200 && postResp.StatusCode() != 201 {
 		return utils.LogError(logger, ctx, "Error during maas agent create client request [HTTP status: %d]", postResp.StatusCode())
 	}
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionCreate, Kind: KindClient, Name: newUsername})
+	c.undoLog.Add(fmt.Sprintf("delete maas client %s", newUsername), func(ctx context.Context) error {
+		return c.deleteMaasClient(ctx, newUsername)
+	})
 
-	err = c.createMaasAgentSecret(ctx, c.Namespace, newUsername, newPassword)
+	err = c.createMaasAgentSecret(ctx, newUsername, newPassword)
 	if err != nil {
 		return utils.LogError(logger, ctx, "failed to create new maas client secret: %w", err)
 	}
@@ -174,40 +255,42 @@ func (c *Configurer) maasAgentCreateClient(ctx context.Context) error {
 	return nil
 }
 
-func (c *Configurer) createMaasAgentSecret(ctx context.Context, namespace, username, password string) error {
-	secretName := "cluster-maas-agent-credentials-secret"
-
-	secret := &v1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/part-of":    "Cloud-Core",
-				"app.kubernetes.io/managed-by": "saasDeployer",
-			},
-		},
-		Data: map[string][]byte{
-			"username": []byte(username),
-			"password": []byte(password),
-		},
-		Type: "Opaque",
-	}
-
-	err := utils.CreateOrUpdateSecret(ctx, c.Namespace, secret)
+func (c *Configurer) deleteMaasClient(ctx context.Context, username string) error {
+	resp, err := c.httpClient.R().
+		SetContext(ctx).
+		SetBasicAuth(c.Username, c.password).
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"username": username}).
+		Delete(fmt.Sprintf("%s/api/v1/auth/account/client", c.Address))
 	if err != nil {
-		return utils.LogError(logger, ctx, "Error creating or updating secret: %w", err)
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("HTTP request failed with status: %d", resp.StatusCode())
 	}
-
-	logger.InfoC(ctx, "Secret %s created/updated successfully", secretName)
 	return nil
 }
 
-func (c *Configurer) getExistingUsernameFromSecret(ctx context.Context, secretName string) (string, error) {
-	secret, err := utils.GetExistingSecret(ctx, c.Namespace, secretName)
+func (c *Configurer) createMaasAgentSecret(ctx context.Context, username, password string) error {
+	restoreSecret, err := credentials.Restorer(ctx, c.sink, agentSecret)
 	if err != nil {
-		return "", err
+		return utils.LogError(logger, ctx, "Error reading secret before update: %w", err)
 	}
+	err = credentials.Save(ctx, c.sink, agentSecret, map[string][]byte{
+		"username": []byte(username),
+		"password": []byte(password),
+	}, nil)
+	if err != nil {
+		return utils.LogError(logger, ctx, "Error creating or updating secret: %w", err)
+	}
+	c.undoLog.Add(fmt.Sprintf("restore secret %s", agentSecret), restoreSecret)
+
+	taskmanager.RecordChange(ctx, taskmanager.Change{Action: taskmanager.ActionApply, Kind: c.sink.Kind(), Name: agentSecret})
+	logger.InfoC(ctx, "Secret %s created/updated successfully", agentSecret)
+	return nil
+}
 
+func usernameFromSecret(secret *credentials.Credentials) (string, error) {
 	if secret == nil {
 		return "", nil
 	}
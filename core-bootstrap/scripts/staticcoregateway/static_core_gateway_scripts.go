@@ -2,35 +2,40 @@ package staticcoregateway
 
 import (
 	"context"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
 	"github.com/netcracker/core-bootstrap/v2/utils"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 )
 
+const TaskName = "staticcoregateway"
+
 type deleteK8sResourceAction struct {
 	resourceName           string
+	resourceType           utils.K8sVersionedResourceType
 	deleteResourceFunction func(ctx context.Context, namespace string, resourceName string) error
 }
 
 var (
 	logger                    = logging.GetLogger("static-core-gateway")
 	deleteK8sResourcesActions = []deleteK8sResourceAction{
-		{"static-core-gateway-pod-monitor", utils.DeleteK8sPodMonitor},
-		{"static-core-gateway", utils.DeleteK8sHorizontalPodAutoscalerV2},
-		{"static-core-gateway", utils.DeleteK8sDeployment},
-		{"static-core-gateway-service", utils.DeleteK8sService},
-		{"static-core-gateway.monitoring-config", utils.DeleteK8sConfigMap},
-		{"config-server-internal", utils.DeleteK8sService},
-		{"control-plane-internal", utils.DeleteK8sService},
-		{"core-operator-internal", utils.DeleteK8sService},
-		{"dbaas-agent-internal", utils.DeleteK8sService},
-		{"identity-provider-internal", utils.DeleteK8sService},
-		{"idp-extensions-internal", utils.DeleteK8sService},
-		{"key-manager-internal", utils.DeleteK8sService},
-		{"maas-agent-internal", utils.DeleteK8sService},
-		{"paas-mediation-internal", utils.DeleteK8sService},
-		{"site-management-internal", utils.DeleteK8sService},
-		{"staas-agent-internal", utils.DeleteK8sService},
-		{"tenant-manager-internal", utils.DeleteK8sService},
+		{"static-core-gateway-pod-monitor", utils.PodMonitorMonitoringCoreosComV1, utils.DeleteK8sPodMonitor},
+		{"static-core-gateway", utils.HorizontalPodAutoscalerAutoscalingV2, utils.DeleteK8sHorizontalPodAutoscalerV2},
+		{"static-core-gateway", utils.DeploymentAppsV1, utils.DeleteK8sDeployment},
+		{"static-core-gateway-service", utils.ServiceV1, utils.DeleteK8sService},
+		{"static-core-gateway.monitoring-config", utils.ConfigMapV1, utils.DeleteK8sConfigMap},
+		{"config-server-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"control-plane-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"core-operator-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"dbaas-agent-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"identity-provider-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"idp-extensions-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"key-manager-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"maas-agent-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"paas-mediation-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"site-management-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"staas-agent-internal", utils.ServiceV1, utils.DeleteK8sService},
+		{"tenant-manager-internal", utils.ServiceV1, utils.DeleteK8sService},
 	}
 )
 
@@ -42,9 +47,14 @@ func New() *Configurer {
 	return &Configurer{}
 }
 
+func (c *Configurer) Name() string {
+	return TaskName
+}
+
 func (c *Configurer) Configure(accessor func(string) string) error {
-	c.Namespace = utils.MustGetEnv(accessor, "NAMESPACE")
-	return nil
+	reader := configsource.NewReader(accessor)
+	c.Namespace = reader.Required("NAMESPACE")
+	return reader.Err()
 }
 
 func (c *Configurer) Execute(ctx context.Context) error {
@@ -52,12 +62,43 @@ func (c *Configurer) Execute(ctx context.Context) error {
 	logger.InfoC(ctx, "Starting delete all static-core-gateway K8s resources in namespace: %s", c.Namespace)
 
 	for _, deleteK8sResourceAction := range deleteK8sResourcesActions {
+		exists, err := utils.K8sResourceExists(ctx, deleteK8sResourceAction.resourceType, c.Namespace, deleteK8sResourceAction.resourceName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			logger.InfoC(ctx, "Skip delete K8s %s '%s' because it is not found", deleteK8sResourceAction.resourceType.Kind(), deleteK8sResourceAction.resourceName)
+			continue
+		}
 		if err := deleteK8sResourceAction.deleteResourceFunction(ctx, c.Namespace, deleteK8sResourceAction.resourceName); err != nil {
 			return err
 		}
+		taskmanager.RecordChange(ctx, taskmanager.Change{
+			Action: taskmanager.ActionDelete,
+			Kind:   deleteK8sResourceAction.resourceType.Kind(),
+			Name:   deleteK8sResourceAction.resourceName,
+		})
 	}
 
 	logger.InfoC(ctx, "Finished delete all static-core-gateway K8s resources in namespace: %s", c.Namespace)
 	logger.InfoC(ctx, "### Finished static_core_gateway_scripts ***")
 	return nil
 }
+
+func (c *Configurer) Plan(ctx context.Context) ([]taskmanager.Change, error) {
+	var changes []taskmanager.Change
+	for _, deleteK8sResourceAction := range deleteK8sResourcesActions {
+		exists, err := utils.K8sResourceExists(ctx, deleteK8sResourceAction.resourceType, c.Namespace, deleteK8sResourceAction.resourceName)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			changes = append(changes, taskmanager.Change{
+				Action: taskmanager.ActionDelete,
+				Kind:   deleteK8sResourceAction.resourceType.Kind(),
+				Name:   deleteK8sResourceAction.resourceName,
+			})
+		}
+	}
+	return changes, nil
+}
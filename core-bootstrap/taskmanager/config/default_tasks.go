@@ -3,7 +3,9 @@ package config
 import (
 	"github.com/netcracker/core-bootstrap/v2/scripts/configserver"
 	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consulkv"
 	"github.com/netcracker/core-bootstrap/v2/scripts/controlplane"
+	"github.com/netcracker/core-bootstrap/v2/scripts/databases"
 	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas"
 	"github.com/netcracker/core-bootstrap/v2/scripts/maas"
 	"github.com/netcracker/core-bootstrap/v2/scripts/staticcoregateway"
@@ -17,8 +19,10 @@ func DefaultTasks() ([]taskmanager.TaskExecutor, []taskmanager.TaskExecutor) {
 	preDeployTasks := []taskmanager.TaskExecutor{
 		consulConfigurer,
 		dbaasConfigurer,
-		controlplane.New(dbaasConfigurer.CreateDatabase),
+		controlplane.New(dbaasConfigurer),
+		databases.New(dbaasConfigurer),
 		configserver.New(consulConfigurer),
+		consulkv.New(consulConfigurer),
 		maas.New(),
 	}
 
@@ -28,3 +32,14 @@ func DefaultTasks() ([]taskmanager.TaskExecutor, []taskmanager.TaskExecutor) {
 
 	return preDeployTasks, postDeployTasks
 }
+
+// UninstallTasks returns tasks removing external resources created by pre-deploy tasks
+func UninstallTasks() []taskmanager.TaskExecutor {
+	consulConfigurer := consul.New()
+	return []taskmanager.TaskExecutor{
+		consulConfigurer,
+		configserver.NewCleanup(consulConfigurer),
+		maas.NewCleanup(),
+		dbaas.NewCleanup(),
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"sigs.k8s.io/yaml"
+)
+
+// PipelineTask declares a registered task in a phase, parameters override configuration values read by the task
+type PipelineTask struct {
+	Name       string            `json:"name"`
+	Phase      taskmanager.Phase `json:"phase"`
+	Enabled    *bool             `json:"enabled,omitempty"`
+	DependsOn  []string          `json:"dependsOn,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// Pipeline is a declarative replacement of DefaultTasks loaded from YAML or JSON file
+type Pipeline struct {
+	Tasks []PipelineTask `json:"tasks"`
+}
+
+func (t PipelineTask) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+func LoadPipeline(path string) (*Pipeline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file '%s': %w", path, err)
+	}
+
+	var pipeline Pipeline
+	if err := yaml.UnmarshalStrict(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline file '%s': %w", path, err)
+	}
+	if err := pipeline.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pipeline file '%s': %w", path, err)
+	}
+	return &pipeline, nil
+}
+
+// Validate reports all problems of the pipeline at once
+func (p *Pipeline) Validate() error {
+	var errs []error
+	declared := make(map[taskmanager.Phase]map[string]bool)
+	byName := make(map[string]PipelineTask)
+	for i, task := range p.Tasks {
+		if task.Name == "" {
+			errs = append(errs, fmt.Errorf("task #%d: name is required", i+1))
+			continue
+		}
+		if _, ok := registry[task.Name]; !ok {
+			errs = append(errs, fmt.Errorf("task '%s': unknown task, registered tasks are: %s", task.Name, strings.Join(RegisteredTasks(), ", ")))
+		}
+		if task.Phase != taskmanager.PreDeployPhase && task.Phase != taskmanager.PostDeployPhase {
+			errs = append(errs, fmt.Errorf("task '%s': phase must be '%s' or '%s', got '%s'", task.Name, taskmanager.PreDeployPhase, taskmanager.PostDeployPhase, task.Phase))
+		}
+		if declared[task.Phase] == nil {
+			declared[task.Phase] = make(map[string]bool)
+		}
+		if declared[task.Phase][task.Name] {
+			errs = append(errs, fmt.Errorf("task '%s': declared more than once in %s phase", task.Name, task.Phase))
+		}
+		declared[task.Phase][task.Name] = true
+		if other, ok := byName[task.Name]; ok && (!reflect.DeepEqual(other.Parameters, task.Parameters) || !slices.Equal(other.DependsOn, task.DependsOn)) {
+			errs = append(errs, fmt.Errorf("task '%s': parameters and dependencies must be the same in all phases", task.Name))
+		}
+		byName[task.Name] = task
+	}
+	return errors.Join(errs...)
+}
+
+// BuildTasks constructs enabled tasks of both phases and their overrides. Task declared in both phases is the same instance.
+func (p *Pipeline) BuildTasks() ([]taskmanager.TaskExecutor, []taskmanager.TaskExecutor, map[string]taskmanager.TaskOverride) {
+	builtins := &Builtins{Consul: consul.New(), Dbaas: dbaas.New()}
+	instances := make(map[string]taskmanager.TaskExecutor)
+	overrides := make(map[string]taskmanager.TaskOverride)
+
+	var preDeployTasks, postDeployTasks []taskmanager.TaskExecutor
+	for _, declaration := range p.Tasks {
+		if !declaration.IsEnabled() {
+			continue
+		}
+		task, ok := instances[declaration.Name]
+		if !ok {
+			task = registry[declaration.Name](builtins)
+			instances[declaration.Name] = task
+		}
+		overrides[taskmanager.TaskName(task)] = taskmanager.TaskOverride{
+			Parameters: declaration.Parameters,
+			DependsOn:  declaration.DependsOn,
+		}
+
+		if declaration.Phase == taskmanager.PostDeployPhase {
+			postDeployTasks = append(postDeployTasks, task)
+		} else {
+			preDeployTasks = append(preDeployTasks, task)
+		}
+	}
+	return preDeployTasks, postDeployTasks, overrides
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"sort"
+
+	"github.com/netcracker/core-bootstrap/v2/scripts/configserver"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consul"
+	"github.com/netcracker/core-bootstrap/v2/scripts/consulkv"
+	"github.com/netcracker/core-bootstrap/v2/scripts/controlplane"
+	"github.com/netcracker/core-bootstrap/v2/scripts/databases"
+	"github.com/netcracker/core-bootstrap/v2/scripts/dbaas"
+	"github.com/netcracker/core-bootstrap/v2/scripts/maas"
+	"github.com/netcracker/core-bootstrap/v2/scripts/staticcoregateway"
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+)
+
+// Builtins holds configurers shared between tasks of one pipeline, e.g. config-server task uses consul configurer
+type Builtins struct {
+	Consul *consul.Configurer
+	Dbaas  *dbaas.Configurer
+}
+
+type TaskConstructor func(builtins *Builtins) taskmanager.TaskExecutor
+
+var registry = map[string]TaskConstructor{
+	consul.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return builtins.Consul
+	},
+	dbaas.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return builtins.Dbaas
+	},
+	databases.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return databases.New(builtins.Dbaas)
+	},
+	controlplane.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return controlplane.New(builtins.Dbaas)
+	},
+	configserver.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return configserver.New(builtins.Consul)
+	},
+	consulkv.TaskName: func(builtins *Builtins) taskmanager.TaskExecutor {
+		return consulkv.New(builtins.Consul)
+	},
+	maas.TaskName: func(*Builtins) taskmanager.TaskExecutor {
+		return maas.New()
+	},
+	staticcoregateway.TaskName: func(*Builtins) taskmanager.TaskExecutor {
+		return staticcoregateway.New()
+	},
+}
+
+// RegisterTask makes a custom task available to pipeline definitions by its name.
+// Must be called before pipeline is loaded, e.g. from init function.
+func RegisterTask(name string, constructor TaskConstructor) {
+	registry[name] = constructor
+}
+
+// RegisteredTasks returns sorted names of all known tasks
+func RegisteredTasks() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -5,16 +5,33 @@ import (
 	"github.com/netcracker/core-bootstrap/v2/taskmanager/config"
 )
 
-func CreateDefaultManager() *taskmanager.TaskManager {
+func CreateDefaultManager(options ...taskmanager.Option) *taskmanager.TaskManager {
 	preDeployTasks, postDeployTasks := config.DefaultTasks()
-	return taskmanager.New(preDeployTasks, postDeployTasks)
+	return taskmanager.New(preDeployTasks, postDeployTasks, withDefaultUninstallTasks(options)...)
 }
 
-func CreateCustomManager(customPreDeployTasks, customPostDeployTasks []taskmanager.TaskExecutor) *taskmanager.TaskManager {
+// withDefaultUninstallTasks puts default uninstall tasks before the options, so the options can replace them
+func withDefaultUninstallTasks(options []taskmanager.Option) []taskmanager.Option {
+	return append([]taskmanager.Option{taskmanager.WithUninstallTasks(config.UninstallTasks()...)}, options...)
+}
+
+func CreateCustomManager(customPreDeployTasks, customPostDeployTasks []taskmanager.TaskExecutor, options ...taskmanager.Option) *taskmanager.TaskManager {
 	defaultPreDeployTasks, defaultPostDeployTasks := config.DefaultTasks()
 
 	allPreDeployTasks := append(defaultPreDeployTasks, customPreDeployTasks...)
 	allPostDeployTasks := append(defaultPostDeployTasks, customPostDeployTasks...)
 
-	return taskmanager.New(allPreDeployTasks, allPostDeployTasks)
+	return taskmanager.New(allPreDeployTasks, allPostDeployTasks, withDefaultUninstallTasks(options)...)
+}
+
+// CreatePipelineManager creates manager executing tasks declared in the pipeline file instead of default ones
+func CreatePipelineManager(path string, options ...taskmanager.Option) (*taskmanager.TaskManager, error) {
+	pipeline, err := config.LoadPipeline(path)
+	if err != nil {
+		return nil, err
+	}
+
+	preDeployTasks, postDeployTasks, overrides := pipeline.BuildTasks()
+	options = append([]taskmanager.Option{taskmanager.WithTaskOverrides(overrides)}, options...)
+	return taskmanager.New(preDeployTasks, postDeployTasks, withDefaultUninstallTasks(options)...), nil
 }
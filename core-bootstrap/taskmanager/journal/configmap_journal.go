@@ -0,0 +1,124 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+	"github.com/netcracker/qubership-core-lib-go/v3/logging"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const DefaultConfigMapName = "core-bootstrap-journal"
+
+var (
+	logger            = logging.GetLogger("journal")
+	invalidKeySymbols = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+)
+
+// ConfigMapJournal stores every journal entry as a separate ConfigMap key `<phase>.<task>'
+type ConfigMapJournal struct {
+	namespace string
+	name      string
+	mutex     sync.Mutex
+}
+
+func NewConfigMapJournal(namespace, name string) *ConfigMapJournal {
+	return &ConfigMapJournal{namespace: namespace, name: name}
+}
+
+func (j *ConfigMapJournal) Load(ctx context.Context, phase taskmanager.Phase) (map[string]taskmanager.JournalEntry, error) {
+	configMap, err := utils.GetExistingConfigMap(ctx, j.namespace, j.name)
+	if err != nil {
+		return nil, utils.LogError(logger, ctx, "error getting journal config map '%s': %w", j.name, err)
+	}
+
+	entries := make(map[string]taskmanager.JournalEntry)
+	if configMap == nil {
+		return entries, nil
+	}
+	for key, value := range configMap.Data {
+		if !strings.HasPrefix(key, keyPrefix(phase)) {
+			continue
+		}
+		var entry taskmanager.JournalEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			logger.WarnC(ctx, "Ignoring malformed journal entry '%s': %v", key, err)
+			continue
+		}
+		entries[entry.Task] = entry
+	}
+	logger.InfoC(ctx, "Loaded %d journal entries of %s phase", len(entries), phase)
+	return entries, nil
+}
+
+func (j *ConfigMapJournal) Record(ctx context.Context, phase taskmanager.Phase, entry taskmanager.JournalEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	return j.update(ctx, func(data map[string]string) {
+		data[entryKey(phase, entry.Task)] = string(value)
+	})
+}
+
+func (j *ConfigMapJournal) Remove(ctx context.Context, phase taskmanager.Phase, task string) error {
+	return j.update(ctx, func(data map[string]string) {
+		delete(data, entryKey(phase, task))
+	})
+}
+
+func (j *ConfigMapJournal) Clear(ctx context.Context, phase taskmanager.Phase) error {
+	return j.update(ctx, func(data map[string]string) {
+		for key := range data {
+			if strings.HasPrefix(key, keyPrefix(phase)) {
+				delete(data, key)
+			}
+		}
+	})
+}
+
+func (j *ConfigMapJournal) update(ctx context.Context, modify func(map[string]string)) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	configMap, err := utils.GetExistingConfigMap(ctx, j.namespace, j.name)
+	if err != nil {
+		return utils.LogError(logger, ctx, "error getting journal config map '%s': %w", j.name, err)
+	}
+	if configMap == nil {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      j.name,
+				Namespace: j.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/part-of":    "Cloud-Core",
+					"app.kubernetes.io/managed-by": "saasDeployer",
+				},
+			},
+		}
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	modify(configMap.Data)
+
+	if err := utils.CreateOrUpdateConfigMap(ctx, j.namespace, configMap); err != nil {
+		return utils.LogError(logger, ctx, "error saving journal config map '%s': %w", j.name, err)
+	}
+	return nil
+}
+
+func keyPrefix(phase taskmanager.Phase) string {
+	return string(phase) + "."
+}
+
+func entryKey(phase taskmanager.Phase, task string) string {
+	return keyPrefix(phase) + invalidKeySymbols.ReplaceAllString(task, "_")
+}
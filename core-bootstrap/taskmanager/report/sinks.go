@@ -0,0 +1,92 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/netcracker/core-bootstrap/v2/taskmanager"
+	"github.com/netcracker/core-bootstrap/v2/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const ConfigMapReportKey = "report.json"
+
+// WriterSink writes the report as a single JSON line, so it can be found as the last line of the job output
+type WriterSink struct {
+	writer io.Writer
+}
+
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{writer: os.Stdout}
+}
+
+func (s *WriterSink) Write(_ context.Context, report *taskmanager.Report) error {
+	return json.NewEncoder(s.writer).Encode(report)
+}
+
+// FileSink overwrites the file with the report, e.g. /dev/termination-log to expose it as pod termination message
+type FileSink struct {
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Write(_ context.Context, report *taskmanager.Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report to '%s': %w", s.path, err)
+	}
+	return nil
+}
+
+// ConfigMapSink stores the report of every phase under its own key of the ConfigMap
+type ConfigMapSink struct {
+	namespace string
+	name      string
+}
+
+func NewConfigMapSink(namespace, name string) *ConfigMapSink {
+	return &ConfigMapSink{namespace: namespace, name: name}
+}
+
+func (s *ConfigMapSink) Write(ctx context.Context, report *taskmanager.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	configMap, err := utils.GetExistingConfigMap(ctx, s.namespace, s.name)
+	if err != nil {
+		return fmt.Errorf("failed to get report config map '%s': %w", s.name, err)
+	}
+	if configMap == nil {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/part-of":    "Cloud-Core",
+					"app.kubernetes.io/managed-by": "saasDeployer",
+				},
+			},
+		}
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[string(report.Phase)+"."+ConfigMapReportKey] = string(data)
+
+	if err := utils.CreateOrUpdateConfigMap(ctx, s.namespace, configMap); err != nil {
+		return fmt.Errorf("failed to save report config map '%s': %w", s.name, err)
+	}
+	return nil
+}
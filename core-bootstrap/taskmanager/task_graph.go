@@ -0,0 +1,127 @@
+package taskmanager
+
+import (
+	"fmt"
+	"strings"
+)
+
+type taskNode struct {
+	name         string
+	task         TaskExecutor
+	dependencies []int
+	dependents   []int
+	inputHash    string
+}
+
+// buildTaskGraph resolves declared and overridden dependencies of the tasks into a DAG. Nodes keep the order of the tasks slice.
+// Tasks without explicit name may share the same type name, but such names cannot be used as dependencies.
+func (tm *TaskManager) buildTaskGraph(tasks []TaskExecutor) ([]*taskNode, error) {
+	graph := make([]*taskNode, len(tasks))
+	byName := make(map[string]int, len(tasks))
+	ambiguous := make(map[string]bool)
+	for i, task := range tasks {
+		name := TaskName(task)
+		graph[i] = &taskNode{name: name, task: task}
+		if _, exists := byName[name]; exists {
+			if _, named := task.(NamedTask); named {
+				return nil, fmt.Errorf("duplicate task name '%s'", name)
+			}
+			ambiguous[name] = true
+		}
+		byName[name] = i
+	}
+
+	for i, node := range graph {
+		var dependencies []string
+		if dependent, ok := node.task.(DependentTask); ok {
+			dependencies = dependent.DependsOn()
+		}
+		dependencies = append(dependencies, tm.overrides[node.name].DependsOn...)
+		for _, dependency := range dependencies {
+			j, exists := byName[dependency]
+			if !exists {
+				return nil, fmt.Errorf("task '%s' depends on unknown task '%s'", node.name, dependency)
+			}
+			if ambiguous[dependency] {
+				return nil, fmt.Errorf("task '%s' depends on ambiguous task name '%s'", node.name, dependency)
+			}
+			node.dependencies = append(node.dependencies, j)
+			graph[j].dependents = append(graph[j].dependents, i)
+		}
+	}
+
+	if cycle := findCycle(graph); len(cycle) > 0 {
+		return nil, fmt.Errorf("task dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+	return graph, nil
+}
+
+// findCycle returns names of tasks forming a dependency cycle or nil if the graph is acyclic.
+func findCycle(graph []*taskNode) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make([]int, len(graph))
+	var path []int
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = inProgress
+		path = append(path, i)
+		for _, j := range graph[i].dependencies {
+			switch state[j] {
+			case inProgress:
+				var cycle []string
+				for k := len(path) - 1; k >= 0; k-- {
+					cycle = append([]string{graph[path[k]].name}, cycle...)
+					if path[k] == j {
+						break
+					}
+				}
+				return append(cycle, graph[j].name)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+		return nil
+	}
+
+	for i := range graph {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// topologicalOrder returns node indexes so that every node goes after its dependencies,
+// keeping the declaration order for independent nodes.
+func topologicalOrder(graph []*taskNode) []int {
+	visited := make([]bool, len(graph))
+	order := make([]int, 0, len(graph))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for _, j := range graph[i].dependencies {
+			visit(j)
+		}
+		order = append(order, i)
+	}
+
+	for i := range graph {
+		visit(i)
+	}
+	return order
+}
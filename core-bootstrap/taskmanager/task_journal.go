@@ -0,0 +1,150 @@
+package taskmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JournalEntry records successful execution of a task.
+type JournalEntry struct {
+	Task        string    `json:"task"`
+	InputHash   string    `json:"inputHash"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// Journal persists task completions between runs of a phase, so a failed run can be resumed.
+// Entries of a phase are cleared once all its tasks succeeded.
+type Journal interface {
+	// Load returns entries of the phase by task name
+	Load(ctx context.Context, phase Phase) (map[string]JournalEntry, error)
+	Record(ctx context.Context, phase Phase, entry JournalEntry) error
+	Remove(ctx context.Context, phase Phase, task string) error
+	Clear(ctx context.Context, phase Phase) error
+}
+
+// InputTask is implemented by tasks whose Execute reads inputs besides configuration values, e.g. mounted files or
+// ConfigMaps. Inputs are read after Configure and added to the hash of task input, so the task is not skipped by
+// the journal once their content changes.
+type InputTask interface {
+	Inputs(context.Context) (map[string][]byte, error)
+}
+
+func (tm *TaskManager) loadJournal(ctx context.Context, phase Phase) map[string]JournalEntry {
+	if tm.journal == nil {
+		return nil
+	}
+	if tm.forceRerun {
+		logger.InfoC(ctx, "Forced rerun: execution journal of %s phase is ignored", phase)
+		return nil
+	}
+
+	entries, err := tm.journal.Load(ctx, phase)
+	if err != nil {
+		logger.WarnC(ctx, "Failed to load execution journal of %s phase, all tasks will be executed: %v", phase, err)
+		return nil
+	}
+	return entries
+}
+
+func (tm *TaskManager) recordJournal(ctx context.Context, phase Phase, node *taskNode) {
+	if tm.journal == nil {
+		return
+	}
+	entry := JournalEntry{Task: node.name, InputHash: node.inputHash, CompletedAt: time.Now().UTC()}
+	if err := tm.journal.Record(ctx, phase, entry); err != nil {
+		logger.WarnC(ctx, "Failed to record task %s in execution journal: %v", node.name, err)
+	}
+}
+
+func (tm *TaskManager) removeJournal(ctx context.Context, phase Phase, node *taskNode) {
+	if tm.journal == nil {
+		return
+	}
+	if err := tm.journal.Remove(ctx, phase, node.name); err != nil {
+		logger.WarnC(ctx, "Failed to remove task %s from execution journal: %v", node.name, err)
+	}
+}
+
+// hashInputs adds inputs of InputTask to the hash of task input, the task is never skipped if they cannot be read
+func (tm *TaskManager) hashInputs(ctx context.Context, node *taskNode, accessor *recordingAccessor) {
+	inputTask, ok := node.task.(InputTask)
+	if !ok {
+		return
+	}
+	inputs, err := inputTask.Inputs(ctx)
+	if err != nil {
+		logger.WarnC(ctx, "Failed to read inputs of task %s, it will be executed: %v", node.name, err)
+		node.inputHash = ""
+		return
+	}
+	for name, content := range inputs {
+		accessor.addInput(name, content)
+	}
+	node.inputHash = accessor.Hash()
+}
+
+// canSkip reports whether the task succeeded before with the same input and none of its dependencies was rerun
+func (tm *TaskManager) canSkip(node *taskNode, journalEntries map[string]JournalEntry, executed []bool) bool {
+	entry, ok := journalEntries[node.name]
+	if !ok || node.inputHash == "" || entry.InputHash != node.inputHash {
+		return false
+	}
+	for _, dependency := range node.dependencies {
+		if executed[dependency] {
+			return false
+		}
+	}
+	return true
+}
+
+// inputPrefix distinguishes inputs of InputTask from configuration values in the hash of task input
+const inputPrefix = "input:"
+
+// recordingAccessor remembers every configuration value read by a task to calculate the hash of task input
+type recordingAccessor struct {
+	accessor func(string) string
+	mutex    sync.Mutex
+	values   map[string]string
+}
+
+func newRecordingAccessor(accessor func(string) string) *recordingAccessor {
+	return &recordingAccessor{accessor: accessor, values: make(map[string]string)}
+}
+
+func (a *recordingAccessor) Get(name string) string {
+	value := a.accessor(name)
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.values[name] = value
+	return value
+}
+
+func (a *recordingAccessor) addInput(name string, content []byte) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.values[inputPrefix+name] = string(content)
+}
+
+func (a *recordingAccessor) Hash() string {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	names := make([]string, 0, len(a.values))
+	for name := range a.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(a.values[name]))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
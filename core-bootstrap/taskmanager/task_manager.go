@@ -2,8 +2,11 @@ package taskmanager
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
+	"time"
 
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 )
@@ -15,41 +18,371 @@ type TaskExecutor interface {
 	Execute(context.Context) error
 }
 
+// NamedTask is implemented by tasks which can be referenced by other tasks as a dependency.
+type NamedTask interface {
+	Name() string
+}
+
+// RollbackTask is implemented by tasks able to revert changes made by their last Execute.
+// Rollback is called in reverse order of completion for succeeded tasks when a later task of the phase fails.
+type RollbackTask interface {
+	Rollback(context.Context) error
+}
+
+// DependentTask is implemented by tasks which must be executed only after the named tasks succeeded.
+type DependentTask interface {
+	DependsOn() []string
+}
+
+type Phase string
+
+const (
+	PreDeployPhase  Phase = "predeploy"
+	PostDeployPhase Phase = "postdeploy"
+	// UninstallPhase removes external resources created for the namespace when it is decommissioned
+	UninstallPhase Phase = "uninstall"
+)
+
+// phases lists all phases in the order of the deployment lifecycle
+var phases = []Phase{PreDeployPhase, PostDeployPhase, UninstallPhase}
+
+// ParsePhase returns the phase by its name
+func ParsePhase(name string) (Phase, error) {
+	switch phase := Phase(name); phase {
+	case PreDeployPhase, PostDeployPhase, UninstallPhase:
+		return phase, nil
+	}
+	return "", fmt.Errorf("unknown phase '%s', expected one of: %s, %s, %s", name, PreDeployPhase, PostDeployPhase, UninstallPhase)
+}
+
 type TaskManager struct {
 	preDeployTasks  []TaskExecutor
 	postDeployTasks []TaskExecutor
+	uninstallTasks  []TaskExecutor
+	journal         Journal
+	forceRerun      bool
+	reportSinks     []ReportSink
+	overrides       map[string]TaskOverride
+	accessor        func(string) string
+}
+
+// TaskOverride customizes a task without changing its implementation
+type TaskOverride struct {
+	// Parameters take precedence over values of the configuration accessor passed to Configure
+	Parameters map[string]string
+	// DependsOn lists dependencies in addition to the ones declared by the task
+	DependsOn []string
+}
+
+type Option func(*TaskManager)
+
+// WithJournal makes the manager skip tasks which already succeeded with the same input during the previous failed run.
+func WithJournal(journal Journal) Option {
+	return func(tm *TaskManager) {
+		tm.journal = journal
+	}
+}
+
+// WithForceRerun makes the manager execute all tasks regardless of the journal.
+func WithForceRerun(forceRerun bool) Option {
+	return func(tm *TaskManager) {
+		tm.forceRerun = forceRerun
+	}
+}
+
+// WithReportSinks makes the manager build execution report of the phase and write it to every sink.
+func WithReportSinks(sinks ...ReportSink) Option {
+	return func(tm *TaskManager) {
+		tm.reportSinks = append(tm.reportSinks, sinks...)
+	}
+}
+
+// WithTaskOverrides applies the overrides to tasks by their names.
+func WithTaskOverrides(overrides map[string]TaskOverride) Option {
+	return func(tm *TaskManager) {
+		tm.overrides = overrides
+	}
 }
 
-func New(preDeployTasks, postDeployTasks []TaskExecutor) *TaskManager {
-	return &TaskManager{
+// WithUninstallTasks sets tasks of the uninstall phase.
+func WithUninstallTasks(tasks ...TaskExecutor) Option {
+	return func(tm *TaskManager) {
+		tm.uninstallTasks = tasks
+	}
+}
+
+// WithAccessor replaces os.Getenv as the source of configuration values passed to Configure of tasks.
+func WithAccessor(accessor func(string) string) Option {
+	return func(tm *TaskManager) {
+		tm.accessor = accessor
+	}
+}
+
+func New(preDeployTasks, postDeployTasks []TaskExecutor, options ...Option) *TaskManager {
+	tm := &TaskManager{
 		preDeployTasks:  preDeployTasks,
 		postDeployTasks: postDeployTasks,
+		accessor:        os.Getenv,
+	}
+	for _, option := range options {
+		option(tm)
+	}
+	return tm
+}
+
+// TaskName returns the name declared by the task or, if the task is not a NamedTask, its Go type name.
+func TaskName(task TaskExecutor) string {
+	if named, ok := task.(NamedTask); ok {
+		return named.Name()
+	}
+	taskType := reflect.TypeOf(task)
+	if taskType.Kind() == reflect.Pointer {
+		taskType = taskType.Elem()
 	}
+	return taskType.PkgPath() + "." + taskType.Name()
 }
 
-func (tm *TaskManager) executeTasks(ctx context.Context, tasks []TaskExecutor) error {
-	for _, task := range tasks {
-		logger.InfoC(ctx, "Configure task: %s.%s", reflect.TypeOf(task).Elem().PkgPath(), reflect.TypeOf(task).Elem().Name())
-		if err := task.Configure(os.Getenv); err != nil {
-			return err
+// Validate checks that tasks of all phases form valid dependency graphs.
+func (tm *TaskManager) Validate() error {
+	for _, phase := range phases {
+		if _, err := tm.buildTaskGraph(tm.tasks(phase)); err != nil {
+			return fmt.Errorf("%s phase: %w", phase, err)
 		}
 	}
+	return nil
+}
 
-	for _, task := range tasks {
-		logger.InfoC(ctx, "Execute task: %s.%s", reflect.TypeOf(task).Elem().PkgPath(), reflect.TypeOf(task).Elem().Name())
-		if err := task.Execute(ctx); err != nil {
-			return err
+func (tm *TaskManager) tasks(phase Phase) []TaskExecutor {
+	switch phase {
+	case PostDeployPhase:
+		return tm.postDeployTasks
+	case UninstallPhase:
+		return tm.uninstallTasks
+	default:
+		return tm.preDeployTasks
+	}
+}
+
+// ValidateConfiguration checks the dependency graph and configures tasks of the phase without executing them.
+func (tm *TaskManager) ValidateConfiguration(ctx context.Context, phase Phase) error {
+	graph, err := tm.buildTaskGraph(tm.tasks(phase))
+	if err != nil {
+		return err
+	}
+	return tm.configureGraph(ctx, graph)
+}
+
+// ValidateAllConfigurations is ValidateConfiguration of every phase, problems of all phases are returned together.
+func (tm *TaskManager) ValidateAllConfigurations(ctx context.Context) error {
+	var errs []error
+	for _, phase := range phases {
+		if err := tm.ValidateConfiguration(ctx, phase); err != nil {
+			errs = append(errs, fmt.Errorf("%s phase: %w", phase, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (tm *TaskManager) executeTasks(ctx context.Context, phase Phase, tasks []TaskExecutor) error {
+	report := newReport(phase)
+	err := tm.executeReportedTasks(ctx, phase, tasks, report)
+	if err != nil {
+		logger.ErrorC(ctx, "Error during %s phase: %v", phase, err)
+	}
+	if len(tm.reportSinks) > 0 {
+		tm.writeReport(ctx, report.finish(err))
+	}
+	return err
+}
+
+func (tm *TaskManager) executeReportedTasks(ctx context.Context, phase Phase, tasks []TaskExecutor, report *Report) error {
+	graph, err := tm.buildTaskGraph(tasks)
+	if err != nil {
+		return err
+	}
+	report.addTasks(graph)
+
+	if err := tm.configureGraph(ctx, graph); err != nil {
+		return err
+	}
+
+	journalEntries := tm.loadJournal(ctx, phase)
+	if err := tm.executeGraph(ctx, phase, graph, journalEntries, report.Tasks); err != nil {
+		return err
+	}
+
+	if tm.journal != nil {
+		if err := tm.journal.Clear(ctx, phase); err != nil {
+			logger.WarnC(ctx, "Failed to clear execution journal of %s phase: %v", phase, err)
 		}
 	}
 	return nil
 }
 
+// configureGraph configures all tasks even if some of them fail, so all configuration problems are reported together
+func (tm *TaskManager) configureGraph(ctx context.Context, graph []*taskNode) error {
+	var errs []error
+	for _, node := range graph {
+		logger.InfoC(ctx, "Configure task: %s", node.name)
+		accessor := newRecordingAccessor(tm.overrides[node.name].accessor(tm.accessor))
+		if err := node.task.Configure(accessor.Get); err != nil {
+			logger.ErrorC(ctx, "Invalid configuration of task %s: %v", node.name, err)
+			errs = append(errs, err)
+			continue
+		}
+		node.inputHash = accessor.Hash()
+		if tm.journal != nil {
+			tm.hashInputs(ctx, node, accessor)
+		}
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+func (o TaskOverride) accessor(accessor func(string) string) func(string) string {
+	if len(o.Parameters) == 0 {
+		return accessor
+	}
+	return func(name string) string {
+		if value, ok := o.Parameters[name]; ok {
+			return value
+		}
+		return accessor(name)
+	}
+}
+
+type taskResult struct {
+	node int
+	err  error
+}
+
+// executeGraph runs every task as soon as all its dependencies succeeded, so independent branches run concurrently.
+// After the first failure no new tasks are started, but already running ones are awaited.
+func (tm *TaskManager) executeGraph(ctx context.Context, phase Phase, graph []*taskNode, journalEntries map[string]JournalEntry, reports []TaskReport) error {
+	pending := make([]int, len(graph))
+	// executed marks tasks actually run in this execution, their dependents cannot be skipped by journal
+	executed := make([]bool, len(graph))
+	var ready []int
+	for i, node := range graph {
+		pending[i] = len(node.dependencies)
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	results := make(chan taskResult)
+	running, finished := 0, 0
+	var completed []int
+	var errs []error
+	complete := func(i int) {
+		for _, dependent := range graph[i].dependents {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	for {
+		for len(errs) == 0 && len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			node := graph[i]
+			if tm.canSkip(node, journalEntries, executed) {
+				logger.InfoC(ctx, "Skip task %s: it already succeeded with the same input", node.name)
+				reports[i].Status = TaskSkipped
+				finished++
+				complete(i)
+				continue
+			}
+
+			logger.InfoC(ctx, "Execute task: %s", node.name)
+			executed[i] = true
+			running++
+			go func() {
+				results <- taskResult{node: i, err: tm.executeTask(ctx, node.task, &reports[i])}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+		finished++
+		node := graph[result.node]
+		if result.err != nil {
+			logger.ErrorC(ctx, "Task %s failed: %v", node.name, result.err)
+			errs = append(errs, result.err)
+			continue
+		}
+		logger.InfoC(ctx, "Task %s finished", node.name)
+		tm.recordJournal(ctx, phase, node)
+		completed = append(completed, result.node)
+		complete(result.node)
+	}
+
+	if skipped := len(graph) - finished; skipped > 0 {
+		logger.WarnC(ctx, "%d task(s) were not executed because of previous failures", skipped)
+	}
+	if len(errs) > 0 {
+		tm.rollback(ctx, phase, graph, completed, reports)
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+func (tm *TaskManager) executeTask(ctx context.Context, task TaskExecutor, report *TaskReport) error {
+	if len(tm.reportSinks) > 0 {
+		recorder := &changeRecorder{}
+		ctx = withChangeRecorder(ctx, recorder)
+		defer func() {
+			report.Resources = recorder.changes
+		}()
+	}
+
+	report.StartedAt = time.Now().UTC()
+	err := task.Execute(ctx)
+	report.FinishedAt = time.Now().UTC()
+	report.setResult(err)
+	return err
+}
+
+// rollback reverts tasks completed in this execution in reverse order, rollback failures are logged and reported only
+func (tm *TaskManager) rollback(ctx context.Context, phase Phase, graph []*taskNode, completed []int, reports []TaskReport) {
+	// changes must be reverted even if the failure was caused by cancellation
+	ctx = context.WithoutCancel(ctx)
+	for k := len(completed) - 1; k >= 0; k-- {
+		node := graph[completed[k]]
+		rollbackTask, ok := node.task.(RollbackTask)
+		if !ok {
+			continue
+		}
+
+		logger.InfoC(ctx, "Rollback task: %s", node.name)
+		report := &reports[completed[k]]
+		if err := rollbackTask.Rollback(ctx); err != nil {
+			logger.ErrorC(ctx, "Rollback of task %s failed: %v", node.name, err)
+			report.RollbackError = err.Error()
+			continue
+		}
+		report.Status = TaskRolledBack
+		tm.removeJournal(ctx, phase, node)
+	}
+}
+
 func (tm *TaskManager) Execute(ctx context.Context, isPostDeployPhase bool) error {
 	if isPostDeployPhase {
-		logger.InfoC(ctx, "Starting postdeploy phase")
-		return tm.executeTasks(ctx, tm.postDeployTasks)
+		return tm.ExecutePhase(ctx, PostDeployPhase)
 	} else {
-		logger.InfoC(ctx, "Starting predeploy phase")
-		return tm.executeTasks(ctx, tm.preDeployTasks)
+		return tm.ExecutePhase(ctx, PreDeployPhase)
 	}
 }
+
+func (tm *TaskManager) ExecutePhase(ctx context.Context, phase Phase) error {
+	logger.InfoC(ctx, "Starting %s phase", phase)
+	return tm.executeTasks(ctx, phase, tm.tasks(phase))
+}
@@ -3,7 +3,11 @@ package taskmanager
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -144,3 +148,532 @@ func TestExecute_EmptyTasks(t *testing.T) {
 	err = tm.Execute(ctx, true)
 	assert.NoError(t, err)
 }
+
+type namedTask struct {
+	name      string
+	dependsOn []string
+	configure func(accessor func(string) string) error
+	execute   func(ctx context.Context) error
+}
+
+func (t *namedTask) Name() string {
+	return t.name
+}
+
+func (t *namedTask) DependsOn() []string {
+	return t.dependsOn
+}
+
+func (t *namedTask) Configure(accessor func(string) string) error {
+	if t.configure == nil {
+		return nil
+	}
+	return t.configure(accessor)
+}
+
+func (t *namedTask) Execute(ctx context.Context) error {
+	if t.execute == nil {
+		return nil
+	}
+	return t.execute(ctx)
+}
+
+func TestExecute_DependenciesOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	// declared in reverse order on purpose
+	tm := New([]TaskExecutor{
+		&namedTask{name: "c", dependsOn: []string{"a", "b"}, execute: record("c")},
+		&namedTask{name: "b", dependsOn: []string{"a"}, execute: record("b")},
+		&namedTask{name: "a", execute: record("a")},
+	}, nil)
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, order)
+}
+
+func TestExecute_IndependentTasksRunConcurrently(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	waitForSibling := func(context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	go func() {
+		<-started
+		<-started
+		close(release)
+	}()
+
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a", execute: waitForSibling},
+		&namedTask{name: "b", execute: waitForSibling},
+	}, nil)
+
+	done := make(chan error)
+	go func() { done <- tm.Execute(context.Background(), false) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("independent tasks were not executed concurrently")
+	}
+}
+
+func TestExecute_DependentSkippedOnFailure(t *testing.T) {
+	expectedErr := errors.New("execution error")
+	dependentExecuted := false
+
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a", execute: func(context.Context) error { return expectedErr }},
+		&namedTask{name: "b", dependsOn: []string{"a"}, execute: func(context.Context) error {
+			dependentExecuted = true
+			return nil
+		}},
+	}, nil)
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.Equal(t, expectedErr, err)
+	assert.False(t, dependentExecuted)
+}
+
+func TestValidate_Cycle(t *testing.T) {
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a", dependsOn: []string{"c"}},
+		&namedTask{name: "b", dependsOn: []string{"a"}},
+		&namedTask{name: "c", dependsOn: []string{"b"}},
+	}, nil)
+
+	err := tm.Validate()
+
+	assert.ErrorContains(t, err, "task dependency cycle detected: a -> c -> b -> a")
+}
+
+func TestValidate_UnknownDependency(t *testing.T) {
+	tm := New(nil, []TaskExecutor{&namedTask{name: "a", dependsOn: []string{"missing"}}})
+
+	err := tm.Validate()
+
+	assert.ErrorContains(t, err, "task 'a' depends on unknown task 'missing'")
+}
+
+func TestValidate_DuplicateName(t *testing.T) {
+	tm := New([]TaskExecutor{&namedTask{name: "a"}, &namedTask{name: "a"}}, nil)
+
+	err := tm.Validate()
+
+	assert.ErrorContains(t, err, "duplicate task name 'a'")
+}
+
+type plannedTask struct {
+	namedTask
+	changes []Change
+}
+
+func (t *plannedTask) Plan(context.Context) ([]Change, error) {
+	return t.changes, nil
+}
+
+func TestPlan(t *testing.T) {
+	executed := false
+	change := Change{Action: ActionCreate, Kind: "Secret", Name: "secret"}
+	tm := New([]TaskExecutor{
+		&plannedTask{
+			namedTask: namedTask{name: "b", dependsOn: []string{"a"}, execute: func(context.Context) error {
+				executed = true
+				return nil
+			}},
+			changes: []Change{change},
+		},
+		&namedTask{name: "a"},
+	}, nil)
+
+	plans, err := tm.Plan(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.False(t, executed)
+	assert.Equal(t, []TaskPlan{
+		{Task: "a", Unsupported: true},
+		{Task: "b", Changes: []Change{change}},
+	}, plans)
+}
+
+type memoryJournal struct {
+	entries map[Phase]map[string]JournalEntry
+}
+
+func newMemoryJournal() *memoryJournal {
+	return &memoryJournal{entries: make(map[Phase]map[string]JournalEntry)}
+}
+
+func (j *memoryJournal) Load(_ context.Context, phase Phase) (map[string]JournalEntry, error) {
+	return j.entries[phase], nil
+}
+
+func (j *memoryJournal) Record(_ context.Context, phase Phase, entry JournalEntry) error {
+	if j.entries[phase] == nil {
+		j.entries[phase] = make(map[string]JournalEntry)
+	}
+	j.entries[phase][entry.Task] = entry
+	return nil
+}
+
+func (j *memoryJournal) Remove(_ context.Context, phase Phase, task string) error {
+	delete(j.entries[phase], task)
+	return nil
+}
+
+func (j *memoryJournal) Clear(_ context.Context, phase Phase) error {
+	delete(j.entries, phase)
+	return nil
+}
+
+func TestExecute_JournalResumesFailedRun(t *testing.T) {
+	t.Setenv("TEST_TASK_INPUT", "value")
+	var mu sync.Mutex
+	executions := make(map[string]int)
+	failing := true
+	task := func(name string, dependsOn ...string) *namedTask {
+		return &namedTask{
+			name:      name,
+			dependsOn: dependsOn,
+			configure: func(accessor func(string) string) error {
+				accessor("TEST_TASK_INPUT")
+				return nil
+			},
+			execute: func(context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				executions[name]++
+				if name == "failing" && failing {
+					return errors.New("transient error")
+				}
+				return nil
+			},
+		}
+	}
+	journal := newMemoryJournal()
+	tm := New([]TaskExecutor{task("a"), task("failing", "a"), task("b", "failing"), task("c")}, nil, WithJournal(journal))
+
+	err := tm.Execute(context.Background(), false)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "failing": 1, "c": 1}, executions)
+	assert.Len(t, journal.entries[PreDeployPhase], 2)
+
+	failing = false
+	err = tm.Execute(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1, "failing": 2, "b": 1, "c": 1}, executions)
+	assert.Empty(t, journal.entries[PreDeployPhase])
+}
+
+func TestExecute_JournalInputChanged(t *testing.T) {
+	executions := 0
+	task := &namedTask{
+		name: "a",
+		configure: func(accessor func(string) string) error {
+			accessor("TEST_TASK_INPUT")
+			return nil
+		},
+		execute: func(context.Context) error {
+			executions++
+			return nil
+		},
+	}
+	journal := newMemoryJournal()
+	tm := New([]TaskExecutor{task}, nil, WithJournal(journal))
+
+	t.Setenv("TEST_TASK_INPUT", "value")
+	_ = journal.Record(context.Background(), PreDeployPhase, JournalEntry{Task: "a", InputHash: "hash of another input"})
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, executions)
+}
+
+func TestExecute_JournalForceRerun(t *testing.T) {
+	executions := 0
+	task := &namedTask{name: "a", execute: func(context.Context) error {
+		executions++
+		return nil
+	}}
+	journal := newMemoryJournal()
+	graph := []*taskNode{{name: "a", task: task}}
+	tm := New([]TaskExecutor{task}, nil, WithJournal(journal), WithForceRerun(true))
+	assert.NoError(t, tm.configureGraph(context.Background(), graph))
+	_ = journal.Record(context.Background(), PreDeployPhase, JournalEntry{Task: "a", InputHash: graph[0].inputHash})
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, executions)
+}
+
+// inputTask is namedTask reading inputs besides configuration values
+type inputTask struct {
+	namedTask
+	inputs func() (map[string][]byte, error)
+}
+
+func (t *inputTask) Inputs(context.Context) (map[string][]byte, error) {
+	return t.inputs()
+}
+
+func TestExecute_JournalInputsChanged(t *testing.T) {
+	executions := 0
+	seed := "key: value"
+	var readErr error
+	task := &inputTask{
+		namedTask: namedTask{name: "a", execute: func(context.Context) error {
+			executions++
+			return nil
+		}},
+		inputs: func() (map[string][]byte, error) {
+			return map[string][]byte{"seed.yaml": []byte(seed)}, readErr
+		},
+	}
+	journal := newMemoryJournal()
+	tm := New([]TaskExecutor{task}, nil, WithJournal(journal))
+	graph := []*taskNode{{name: "a", task: task}}
+	assert.NoError(t, tm.configureGraph(context.Background(), graph))
+	record := func() {
+		_ = journal.Record(context.Background(), PreDeployPhase, JournalEntry{Task: "a", InputHash: graph[0].inputHash})
+	}
+
+	record()
+	assert.NoError(t, tm.Execute(context.Background(), false))
+	assert.Equal(t, 0, executions, "skipped with the same inputs")
+
+	record()
+	seed = "key: changed"
+	assert.NoError(t, tm.Execute(context.Background(), false))
+	assert.Equal(t, 1, executions, "executed once inputs changed")
+
+	seed = "key: value"
+	record()
+	readErr = errors.New("config map not found")
+	assert.NoError(t, tm.Execute(context.Background(), false))
+	assert.Equal(t, 2, executions, "executed if inputs cannot be read")
+}
+
+type memorySink struct {
+	reports []*Report
+}
+
+func (s *memorySink) Write(_ context.Context, report *Report) error {
+	s.reports = append(s.reports, report)
+	return nil
+}
+
+func TestExecute_Report(t *testing.T) {
+	expectedErr := errors.New("execution error")
+	change := Change{Action: ActionCreate, Kind: "Secret", Name: "secret"}
+	sink := &memorySink{}
+	tm := New(nil, []TaskExecutor{
+		&namedTask{name: "a", execute: func(ctx context.Context) error {
+			RecordChange(ctx, change)
+			return nil
+		}},
+		&namedTask{name: "b", dependsOn: []string{"a"}, execute: func(context.Context) error { return expectedErr }},
+		&namedTask{name: "c", dependsOn: []string{"b"}},
+	}, WithReportSinks(sink))
+
+	err := tm.Execute(context.Background(), true)
+
+	assert.Equal(t, expectedErr, err)
+	assert.Len(t, sink.reports, 1)
+	report := sink.reports[0]
+	assert.Equal(t, PostDeployPhase, report.Phase)
+	assert.Equal(t, TaskFailed, report.Status)
+	assert.Equal(t, "execution error", report.Error)
+	assert.Len(t, report.Tasks, 3)
+	assert.Equal(t, TaskSucceeded, report.Tasks[0].Status)
+	assert.Equal(t, []Change{change}, report.Tasks[0].Resources)
+	assert.False(t, report.Tasks[0].StartedAt.IsZero())
+	assert.Equal(t, TaskFailed, report.Tasks[1].Status)
+	assert.Equal(t, "execution error", report.Tasks[1].Error)
+	assert.Equal(t, TaskNotStarted, report.Tasks[2].Status)
+}
+
+type rollbackTask struct {
+	namedTask
+	rollback func(ctx context.Context) error
+}
+
+func (t *rollbackTask) Rollback(ctx context.Context) error {
+	return t.rollback(ctx)
+}
+
+func TestExecute_RollbackOnFailure(t *testing.T) {
+	expectedErr := errors.New("execution error")
+	var rolledBack []string
+	rollback := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			rolledBack = append(rolledBack, name)
+			return nil
+		}
+	}
+	journal := newMemoryJournal()
+	sink := &memorySink{}
+	tm := New([]TaskExecutor{
+		&rollbackTask{namedTask: namedTask{name: "a"}, rollback: rollback("a")},
+		&namedTask{name: "b", dependsOn: []string{"a"}},
+		&rollbackTask{namedTask: namedTask{name: "c", dependsOn: []string{"b"}}, rollback: rollback("c")},
+		&rollbackTask{
+			namedTask: namedTask{name: "d", dependsOn: []string{"c"}, execute: func(context.Context) error { return expectedErr }},
+			rollback:  rollback("d"),
+		},
+	}, nil, WithJournal(journal), WithReportSinks(sink))
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, []string{"c", "a"}, rolledBack)
+	assert.Equal(t, []string{"b"}, slices.Collect(maps.Keys(journal.entries[PreDeployPhase])))
+	tasks := sink.reports[0].Tasks
+	assert.Equal(t, TaskRolledBack, tasks[0].Status)
+	assert.Equal(t, TaskSucceeded, tasks[1].Status)
+	assert.Equal(t, TaskRolledBack, tasks[2].Status)
+	assert.Equal(t, TaskFailed, tasks[3].Status)
+}
+
+func TestExecute_RollbackError(t *testing.T) {
+	expectedErr := errors.New("execution error")
+	sink := &memorySink{}
+	tm := New([]TaskExecutor{
+		&rollbackTask{namedTask: namedTask{name: "a"}, rollback: func(context.Context) error { return errors.New("rollback error") }},
+		&namedTask{name: "b", dependsOn: []string{"a"}, execute: func(context.Context) error { return expectedErr }},
+	}, nil, WithReportSinks(sink))
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, TaskSucceeded, sink.reports[0].Tasks[0].Status)
+	assert.Equal(t, "rollback error", sink.reports[0].Tasks[0].RollbackError)
+}
+
+func TestExecute_TaskOverrides(t *testing.T) {
+	t.Setenv("TEST_TASK_INPUT", "env")
+	t.Setenv("TEST_TASK_OTHER_INPUT", "env")
+	var order []string
+	var configured []string
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a",
+			configure: func(accessor func(string) string) error {
+				configured = append(configured, accessor("TEST_TASK_INPUT"), accessor("TEST_TASK_OTHER_INPUT"))
+				return nil
+			},
+			execute: func(context.Context) error {
+				order = append(order, "a")
+				return nil
+			}},
+		&namedTask{name: "b", execute: func(context.Context) error {
+			order = append(order, "b")
+			return nil
+		}},
+	}, nil, WithTaskOverrides(map[string]TaskOverride{
+		"a": {Parameters: map[string]string{"TEST_TASK_INPUT": "override"}, DependsOn: []string{"b"}},
+	}))
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"override", "env"}, configured)
+	assert.Equal(t, []string{"b", "a"}, order)
+}
+
+func TestExecute_WithAccessor(t *testing.T) {
+	t.Setenv("TEST_TASK_INPUT", "env")
+	var configured string
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a", configure: func(accessor func(string) string) error {
+			configured = accessor("TEST_TASK_INPUT")
+			return nil
+		}},
+	}, nil, WithAccessor(func(name string) string {
+		return "custom-" + name
+	}))
+
+	err := tm.Execute(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-TEST_TASK_INPUT", configured)
+}
+
+func TestValidateConfiguration_CollectsErrorsOfAllTasks(t *testing.T) {
+	errA := errors.New("a is misconfigured")
+	errB := errors.New("b is misconfigured")
+	executed := false
+	tm := New([]TaskExecutor{
+		&namedTask{name: "a", configure: func(func(string) string) error { return errA }},
+		&namedTask{name: "b", configure: func(func(string) string) error { return errB }},
+		&namedTask{name: "c", execute: func(context.Context) error {
+			executed = true
+			return nil
+		}},
+	}, nil)
+
+	err := tm.ValidateConfiguration(context.Background(), PreDeployPhase)
+
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+	assert.False(t, executed)
+}
+
+func TestValidateAllConfigurations_CollectsErrorsOfAllPhases(t *testing.T) {
+	errPre := errors.New("pre-deploy task is misconfigured")
+	errUninstall := errors.New("uninstall task is misconfigured")
+	tm := New([]TaskExecutor{
+		&namedTask{name: "install", configure: func(func(string) string) error { return errPre }},
+	}, []TaskExecutor{
+		&namedTask{name: "gateway"},
+	}, WithUninstallTasks(&namedTask{name: "cleanup", configure: func(func(string) string) error { return errUninstall }}))
+
+	err := tm.ValidateAllConfigurations(context.Background())
+
+	assert.ErrorIs(t, err, errPre)
+	assert.ErrorIs(t, err, errUninstall)
+	assert.ErrorContains(t, err, "predeploy phase: ")
+	assert.ErrorContains(t, err, "uninstall phase: ")
+	assert.NotContains(t, err.Error(), "postdeploy")
+}
+
+func TestExecutePhase_Uninstall(t *testing.T) {
+	var executed []string
+	task := func(name string) *namedTask {
+		return &namedTask{name: name, execute: func(context.Context) error {
+			executed = append(executed, name)
+			return nil
+		}}
+	}
+	tm := New([]TaskExecutor{task("install")}, nil, WithUninstallTasks(task("uninstall")))
+
+	err := tm.ExecutePhase(context.Background(), UninstallPhase)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"uninstall"}, executed)
+}
+
+func TestParsePhase(t *testing.T) {
+	phase, err := ParsePhase("uninstall")
+	assert.NoError(t, err)
+	assert.Equal(t, UninstallPhase, phase)
+
+	_, err = ParsePhase("cleanup")
+	assert.ErrorContains(t, err, "unknown phase 'cleanup'")
+}
@@ -0,0 +1,83 @@
+package taskmanager
+
+import (
+	"context"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	// ActionApply is used for idempotent create-or-update calls when the current state cannot be read
+	ActionApply  Action = "apply"
+	ActionDelete Action = "delete"
+	ActionNone   Action = "none"
+	// ActionDrift reports a resource modified outside core-bootstrap since it was written last, it is not a change itself
+	ActionDrift Action = "drift"
+)
+
+// Change describes a single modification of an external system which a task makes or would make.
+type Change struct {
+	Action Action `json:"action"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Planner is implemented by tasks able to report the changes they would make without making them.
+// Plan is called after Configure and must not modify anything.
+type Planner interface {
+	Plan(context.Context) ([]Change, error)
+}
+
+// CreateOrUpdate returns the action of an upsert for the given existence of the resource
+func CreateOrUpdate(exists bool) Action {
+	if exists {
+		return ActionUpdate
+	}
+	return ActionCreate
+}
+
+type TaskPlan struct {
+	Task        string   `json:"task"`
+	Unsupported bool     `json:"unsupported,omitempty"`
+	Changes     []Change `json:"changes"`
+}
+
+// Plan configures tasks of the phase and collects plans of all of them in dependency order.
+func (tm *TaskManager) Plan(ctx context.Context, isPostDeployPhase bool) ([]TaskPlan, error) {
+	if isPostDeployPhase {
+		return tm.PlanPhase(ctx, PostDeployPhase)
+	}
+	return tm.PlanPhase(ctx, PreDeployPhase)
+}
+
+func (tm *TaskManager) PlanPhase(ctx context.Context, phase Phase) ([]TaskPlan, error) {
+	graph, err := tm.buildTaskGraph(tm.tasks(phase))
+	if err != nil {
+		return nil, err
+	}
+	if err := tm.configureGraph(ctx, graph); err != nil {
+		return nil, err
+	}
+
+	plans := make([]TaskPlan, 0, len(graph))
+	for _, i := range topologicalOrder(graph) {
+		node := graph[i]
+		planner, ok := node.task.(Planner)
+		if !ok {
+			logger.WarnC(ctx, "Task %s does not support plan mode", node.name)
+			plans = append(plans, TaskPlan{Task: node.name, Unsupported: true})
+			continue
+		}
+
+		logger.InfoC(ctx, "Plan task: %s", node.name)
+		changes, err := planner.Plan(ctx)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, TaskPlan{Task: node.name, Changes: changes})
+	}
+	return plans, nil
+}
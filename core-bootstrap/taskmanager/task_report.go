@@ -0,0 +1,102 @@
+package taskmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type TaskStatus string
+
+const (
+	TaskSucceeded  TaskStatus = "succeeded"
+	TaskFailed     TaskStatus = "failed"
+	TaskSkipped    TaskStatus = "skipped"
+	TaskNotStarted TaskStatus = "not-started"
+	TaskRolledBack TaskStatus = "rolled-back"
+)
+
+type TaskReport struct {
+	Task          string     `json:"task"`
+	Phase         Phase      `json:"phase"`
+	Status        TaskStatus `json:"status"`
+	StartedAt     time.Time  `json:"startedAt,omitzero"`
+	FinishedAt    time.Time  `json:"finishedAt,omitzero"`
+	Error         string     `json:"error,omitempty"`
+	RollbackError string     `json:"rollbackError,omitempty"`
+	Resources     []Change   `json:"resources,omitempty"`
+}
+
+// Report describes execution of a phase, it is written to report sinks after the phase finishes.
+type Report struct {
+	Phase      Phase        `json:"phase"`
+	Status     TaskStatus   `json:"status"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Error      string       `json:"error,omitempty"`
+	Tasks      []TaskReport `json:"tasks"`
+}
+
+type ReportSink interface {
+	Write(ctx context.Context, report *Report) error
+}
+
+func newReport(phase Phase) *Report {
+	return &Report{Phase: phase, StartedAt: time.Now().UTC(), Tasks: []TaskReport{}}
+}
+
+func (r *Report) addTasks(graph []*taskNode) {
+	r.Tasks = make([]TaskReport, len(graph))
+	for i, node := range graph {
+		r.Tasks[i] = TaskReport{Task: node.name, Phase: r.Phase, Status: TaskNotStarted}
+	}
+}
+
+func (r *Report) finish(err error) *Report {
+	r.FinishedAt = time.Now().UTC()
+	r.Status = TaskSucceeded
+	if err != nil {
+		r.Status = TaskFailed
+		r.Error = err.Error()
+	}
+	return r
+}
+
+func (r *TaskReport) setResult(err error) {
+	r.Status = TaskSucceeded
+	if err != nil {
+		r.Status = TaskFailed
+		r.Error = err.Error()
+	}
+}
+
+func (tm *TaskManager) writeReport(ctx context.Context, report *Report) {
+	for _, sink := range tm.reportSinks {
+		if err := sink.Write(ctx, report); err != nil {
+			logger.WarnC(ctx, "Failed to write execution report: %v", err)
+		}
+	}
+}
+
+type changeRecorderKey struct{}
+
+type changeRecorder struct {
+	mutex   sync.Mutex
+	changes []Change
+}
+
+func withChangeRecorder(ctx context.Context, recorder *changeRecorder) context.Context {
+	return context.WithValue(ctx, changeRecorderKey{}, recorder)
+}
+
+// RecordChange adds the change made by the currently executed task to the execution report.
+// It does nothing if the report is not collected.
+func RecordChange(ctx context.Context, change Change) {
+	recorder, ok := ctx.Value(changeRecorderKey{}).(*changeRecorder)
+	if !ok {
+		return
+	}
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.changes = append(recorder.changes, change)
+}
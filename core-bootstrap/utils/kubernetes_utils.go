@@ -7,9 +7,15 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/csaupgrade"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -21,6 +27,7 @@ const (
 	ConfigMapV1
 	HorizontalPodAutoscalerAutoscalingV2
 	PodMonitorMonitoringCoreosComV1
+	SecretV1
 )
 
 func (resourceType K8sVersionedResourceType) Kind() string {
@@ -35,6 +42,8 @@ func (resourceType K8sVersionedResourceType) Kind() string {
 		return "HorizontalPodAutoscaler"
 	case PodMonitorMonitoringCoreosComV1:
 		return "PodMonitor"
+	case SecretV1:
+		return "Secret"
 	default:
 		return fmt.Sprintf("<unknown Kind of K8sVersionedResourceType: %v>", int(resourceType))
 	}
@@ -44,7 +53,7 @@ func (resourceType K8sVersionedResourceType) AppVersion() string {
 	switch resourceType {
 	case DeploymentAppsV1:
 		return "apps/v1"
-	case ServiceV1, ConfigMapV1:
+	case ServiceV1, ConfigMapV1, SecretV1:
 		return "v1"
 	case HorizontalPodAutoscalerAutoscalingV2:
 		return "autoscaling/v2"
@@ -55,6 +64,25 @@ func (resourceType K8sVersionedResourceType) AppVersion() string {
 	}
 }
 
+func (resourceType K8sVersionedResourceType) GroupVersionResource() schema.GroupVersionResource {
+	switch resourceType {
+	case DeploymentAppsV1:
+		return schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	case ServiceV1:
+		return schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	case ConfigMapV1:
+		return schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	case HorizontalPodAutoscalerAutoscalingV2:
+		return schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+	case PodMonitorMonitoringCoreosComV1:
+		return schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"}
+	case SecretV1:
+		return schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	default:
+		return schema.GroupVersionResource{}
+	}
+}
+
 var (
 	K8sClient        *kubernetes.Clientset
 	K8sDynamicClient *dynamic.DynamicClient
@@ -100,38 +128,82 @@ func GetExistingSecret(ctx context.Context, namespace string, secretName string)
 	return secret, nil
 }
 
-func CreateOrUpdateSecret(ctx context.Context, namespace string, secret *v1.Secret) error {
-	_, err := K8sClient.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+// FieldManager owns fields of resources written by server-side apply
+const FieldManager = "core-bootstrap"
+
+// ApplySecret writes labels, annotations and data of the Opaque secret by server-side apply, so labels, annotations
+// and keys set by others are kept. Non-empty resourceVersion makes the apply fail if the secret was modified since.
+// Fields written by previous versions with Create and Update calls are moved to FieldManager first,
+// otherwise values removed from the applied configuration would stay in the secret.
+func ApplySecret(ctx context.Context, namespace, name string, labels, annotations map[string]string, data map[string][]byte, resourceVersion string) error {
+	secrets := K8sClient.CoreV1().Secrets(namespace)
+	live, err := GetExistingSecret(ctx, namespace, name)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			_, updateErr := K8sClient.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
-			return updateErr
+		return err
+	}
+	if live == nil && resourceVersion != "" {
+		return apierrors.NewNotFound(SecretV1.GroupVersionResource().GroupResource(), name)
+	}
+	if live != nil {
+		if resourceVersion != "" && live.ResourceVersion != resourceVersion {
+			return apierrors.NewConflict(SecretV1.GroupVersionResource().GroupResource(), name,
+				fmt.Errorf("secret was modified since version %s was read", resourceVersion))
+		}
+		patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, sets.New(filepath.Base(os.Args[0])), FieldManager)
+		if err != nil {
+			return fmt.Errorf("error migrating managed fields of secret %s: %w", name, err)
 		}
+		if patch != nil {
+			if live, err = secrets.Patch(ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("error migrating managed fields of secret %s: %w", name, err)
+			}
+		}
+		if resourceVersion != "" {
+			resourceVersion = live.ResourceVersion
+		}
+	}
+
+	secret := corev1ac.Secret(name, namespace).
+		WithLabels(labels).
+		WithAnnotations(annotations).
+		WithData(data).
+		WithType(v1.SecretTypeOpaque)
+	if resourceVersion != "" {
+		secret.WithResourceVersion(resourceVersion)
+	}
+	_, err = secrets.Apply(ctx, secret, metav1.ApplyOptions{FieldManager: FieldManager, Force: true})
+	return err
+}
+
+func DeleteSecret(ctx context.Context, namespace string, secretName string) error {
+	err := K8sClient.CoreV1().Secrets(namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 	return nil
 }
 
-func CreateSecretWithDbCredsData(ctx context.Context, namespace string, secretName string, data map[string][]byte) error {
-	secret := &v1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/part-of":    "Cloud-Core",
-				"app.kubernetes.io/managed-by": "saasDeployer",
-			},
-		},
-		Data: data,
-		Type: "Opaque",
+func GetExistingConfigMap(ctx context.Context, namespace string, configMapName string) (*v1.ConfigMap, error) {
+	configMap, err := K8sClient.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
 
-	err := CreateOrUpdateSecret(ctx, namespace, secret)
+	return configMap, nil
+}
+
+func CreateOrUpdateConfigMap(ctx context.Context, namespace string, configMap *v1.ConfigMap) error {
+	_, err := K8sClient.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{})
 	if err != nil {
-		return LogError(logger, ctx, "Error creating db secret: %v", err)
+		if apierrors.IsAlreadyExists(err) {
+			_, updateErr := K8sClient.CoreV1().ConfigMaps(namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+			return updateErr
+		}
+		return err
 	}
-
-	logger.InfoC(ctx, fmt.Sprintf("Secret %s created successfully", secretName))
 	return nil
 }
 
@@ -161,15 +233,22 @@ func DeleteK8sHorizontalPodAutoscalerV2(ctx context.Context, namespace string, h
 
 func DeleteK8sPodMonitor(ctx context.Context, namespace string, podMonitorName string) error {
 	return deleteK8sResource(ctx, PodMonitorMonitoringCoreosComV1, namespace, podMonitorName, func(ctx context.Context, k8sResourceName string) error {
-		gvr := schema.GroupVersionResource{
-			Group:    "monitoring.coreos.com",
-			Version:  "v1",
-			Resource: "podmonitors",
-		}
-		return K8sDynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, podMonitorName, metav1.DeleteOptions{})
+		return K8sDynamicClient.Resource(PodMonitorMonitoringCoreosComV1.GroupVersionResource()).Namespace(namespace).Delete(ctx, podMonitorName, metav1.DeleteOptions{})
 	})
 }
 
+// K8sResourceExists checks existence of the resource without modifying it, missing CRDs are treated as absent resource
+func K8sResourceExists(ctx context.Context, k8sResourceType K8sVersionedResourceType, namespace string, k8sResourceName string) (bool, error) {
+	_, err := K8sDynamicClient.Resource(k8sResourceType.GroupVersionResource()).Namespace(namespace).Get(ctx, k8sResourceName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, LogError(logger, ctx, "Error getting K8s %s with appVersion '%s' and name '%s' in namespace '%s': %v", k8sResourceType.Kind(), k8sResourceType.AppVersion(), k8sResourceName, namespace, err)
+	}
+	return true, nil
+}
+
 func deleteK8sResource(ctx context.Context, k8sResourceType K8sVersionedResourceType, namespace string, k8sResourceName string, deleteK8sResourceFunction func(context.Context, string) error) error {
 	logger.InfoC(ctx, "Deleting K8s %s with appVersion '%s' and name '%s' in namespace '%s'", k8sResourceType.Kind(), k8sResourceType.AppVersion(), k8sResourceName, namespace)
 
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type undoAction struct {
+	description string
+	undo        func(context.Context) error
+}
+
+// UndoLog collects actions reverting changes made by a task, so they can be rolled back in reverse order
+type UndoLog struct {
+	mutex   sync.Mutex
+	actions []undoAction
+}
+
+func (l *UndoLog) Add(description string, undo func(context.Context) error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.actions = append(l.actions, undoAction{description: description, undo: undo})
+}
+
+// Rollback runs all collected actions in reverse order and clears the log. Failed actions do not stop the rollback.
+func (l *UndoLog) Rollback(ctx context.Context) error {
+	l.mutex.Lock()
+	actions := l.actions
+	l.actions = nil
+	l.mutex.Unlock()
+
+	var errs []error
+	for i := len(actions) - 1; i >= 0; i-- {
+		logger.InfoC(ctx, "Rollback: %s", actions[i].description)
+		if err := actions[i].undo(ctx); err != nil {
+			errs = append(errs, LogError(logger, ctx, "error during rollback '%s': %w", actions[i].description, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -2,11 +2,10 @@ package utils
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/tls"
-	"encoding/hex"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/netcracker/core-bootstrap/v2/configsource"
+	"github.com/netcracker/core-bootstrap/v2/httpclient"
 	"github.com/netcracker/qubership-core-lib-go/v3/logging"
 	"os"
 	"os/signal"
@@ -15,11 +14,15 @@ import (
 	"time"
 )
 
+// tlsSecretsTimeout limits reading of TLS secrets of an endpoint, so unavailable API server cannot block startup
+const tlsSecretsTimeout = 30 * time.Second
+
 var (
 	logger      = logging.GetLogger("utils")
-	RestyClient = newRestyClient()
+	RestyClient = httpclient.New("default", httpclient.DefaultProfile, nil)
 )
 
+// Deprecated: panics on the first missing value, use configsource.Reader to report all problems of the configuration together
 func MustGetEnv(accessor func(string) string, name string) string {
 	value := accessor(name)
 	if value == "" {
@@ -33,35 +36,38 @@ func GetEnvBoolean(accessor func(string) string, name string) bool {
 	return strings.ToLower(value) == "true"
 }
 
-func GeneratePassword(size int) string {
-	timestamp := time.Now().UnixNano()
-	hash := sha256.Sum256([]byte(fmt.Sprintf("%d", timestamp)))
-	password := hex.EncodeToString(hash[:])
-
-	if size < len(password) {
-		return password[:size]
+// NewEndpointClient returns HTTP client of the endpoint, e.g. `consul', with the profile and TLS settings read by
+// httpclient.ReadProfile and httpclient.ReadTLSConfig; problems are recorded in the reader and the default client
+// is returned then.
+// Secrets with CA bundles and client certificates are read from the namespace within tlsSecretsTimeout.
+func NewEndpointClient(reader *configsource.Reader, endpoint, namespace string) *resty.Client {
+	profile := httpclient.ReadProfile(reader, endpoint)
+	tlsConfig := httpclient.ReadTLSConfig(reader, endpoint)
+	if tlsConfig.InsecureSkipVerify {
+		logger.Warn("TLS certificate verification of %s endpoint is disabled, credentials sent to it can be intercepted", endpoint)
 	}
-	return password
-}
-
-func newRestyClient() *resty.Client {
-	client := resty.New()
-	client.SetDisableWarn(true)
-
-	client.SetTimeout(10 * time.Second)
-
-	client.SetRetryCount(3)
-	client.SetRetryWaitTime(2 * time.Second)
-	client.SetRetryMaxWaitTime(10 * time.Second)
-
-	client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
-
-	return client
+	ctx, cancel := context.WithTimeout(context.Background(), tlsSecretsTimeout)
+	defer cancel()
+	config, err := tlsConfig.Build(func(name string) (map[string][]byte, error) {
+		secret, err := GetExistingSecret(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil {
+			return nil, fmt.Errorf("secret %s is not found", name)
+		}
+		return secret.Data, nil
+	})
+	if err != nil {
+		reader.Fail("invalid TLS configuration of %s endpoint: %w", endpoint, err)
+		return RestyClient
+	}
+	return httpclient.New(endpoint, profile, config)
 }
 
 func LogError(log logging.Logger, ctx context.Context, format string, args ...any) error {
 	s := fmt.Errorf(format, args...)
-	log.ErrorC(ctx, s.Error())
+	log.ErrorC(ctx, "%s", s.Error())
 	return s
 }
 